@@ -0,0 +1,113 @@
+package autoscaler
+
+import (
+	"context"
+	"fmt"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	NodeGroupSourceKarpenter         = "karpenter"
+	NodeGroupSourceClusterAutoscaler = "cluster-autoscaler"
+)
+
+type IAutoscalerClient interface {
+	GetNodeGroups(ctx context.Context) ([]NodeGroup, error)
+	GetLaunchableComputeConfigurations(ctx context.Context, configurations []ultron.ComputeConfiguration, regions []string) (*[]ultron.ComputeConfiguration, error)
+}
+
+// NodeGroup is a Karpenter NodePool or Cluster Autoscaler node group. Requirements use the node labels that
+// the launched nodes would carry, such as node.kubernetes.io/instance-type and karpenter.sh/capacity-type.
+type NodeGroup struct {
+	Source       string
+	Name         string
+	Requirements []corev1.NodeSelectorRequirement
+}
+
+// AutoscalerClient reads the node groups that Karpenter and the Cluster Autoscaler (through Cluster API) may
+// scale up. Autoscalers that are not installed contribute no node groups.
+type AutoscalerClient struct {
+	Client dynamic.Interface
+}
+
+func NewAutoscalerClient(config *attendant.Config) (*AutoscalerClient, error) {
+	masterUrl := config.KubernetesMasterUrl
+	if masterUrl == "https://:" {
+		masterUrl = ""
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags(masterUrl, config.KubernetesConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AutoscalerClient{Client: client}, nil
+}
+
+func (ac *AutoscalerClient) GetNodeGroups(ctx context.Context) ([]NodeGroup, error) {
+	karpenterGroups, err := ac.getKarpenterNodeGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Karpenter node pools: %v", err)
+	}
+
+	clusterApiGroups, err := ac.getClusterApiNodeGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Cluster Autoscaler node groups: %v", err)
+	}
+
+	return append(karpenterGroups, clusterApiGroups...), nil
+}
+
+// GetLaunchableComputeConfigurations returns the priced configurations that at least one node group is allowed
+// to launch. Node groups without a region requirement are limited to the regions the cluster runs in.
+func (ac *AutoscalerClient) GetLaunchableComputeConfigurations(ctx context.Context, configurations []ultron.ComputeConfiguration, regions []string) (*[]ultron.ComputeConfiguration, error) {
+	groups, err := ac.GetNodeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ExpandNodeGroups(groups, configurations, regions)
+
+	return &result, nil
+}
+
+// listResources lists the first of the given resource versions the API server serves. It returns no items
+// when none is served, e.g. because the CRD is not installed.
+func (ac *AutoscalerClient) listResources(ctx context.Context, resources ...schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	for _, resource := range resources {
+		list, err := ac.Client.Resource(resource).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return list.Items, nil
+	}
+
+	return nil, nil
+}
+
+func (ac *AutoscalerClient) getResource(ctx context.Context, resource schema.GroupVersionResource, namespace string, name string) (*unstructured.Unstructured, error) {
+	if namespace == "" {
+		return ac.Client.Resource(resource).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	return ac.Client.Resource(resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -0,0 +1,171 @@
+package autoscaler_test
+
+import (
+	"context"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/autoscaler"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newObject(apiVersion string, kind string, namespace string, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: fields}
+	object.SetAPIVersion(apiVersion)
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+
+	return object
+}
+
+func newReadyStatus(status string) map[string]interface{} {
+	return map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": status}},
+	}
+}
+
+func newNodePool(name string, nodeClass string, requirements ...interface{}) *unstructured.Unstructured {
+	return newObject("karpenter.sh/v1", "NodePool", "", name, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"team": "data"},
+				},
+				"spec": map[string]interface{}{
+					"nodeClassRef": map[string]interface{}{"group": "karpenter.k8s.aws", "kind": "EC2NodeClass", "name": nodeClass},
+					"requirements": requirements,
+				},
+			},
+		},
+	})
+}
+
+func newRequirement(key string, operator string, values ...interface{}) map[string]interface{} {
+	return map[string]interface{}{"key": key, "operator": operator, "values": values}
+}
+
+func newMachineDeployment(name string, annotations map[string]string, template string) *unstructured.Unstructured {
+	object := newObject("cluster.x-k8s.io/v1beta1", "MachineDeployment", "clusters", name, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"infrastructureRef": map[string]interface{}{
+						"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2",
+						"kind":       "AWSMachineTemplate",
+						"name":       template,
+					},
+				},
+			},
+		},
+	})
+	object.SetAnnotations(annotations)
+
+	return object
+}
+
+func newAutoscalerClient(objects ...runtime.Object) *wrapper.AutoscalerClient {
+	listKinds := map[schema.GroupVersionResource]string{
+		wrapper.KarpenterNodePoolV1:         "NodePoolList",
+		wrapper.KarpenterNodePoolV1beta1:    "NodePoolList",
+		wrapper.ClusterApiMachineDeployment: "MachineDeploymentList",
+		wrapper.ClusterApiMachineSet:        "MachineSetList",
+	}
+
+	return &wrapper.AutoscalerClient{Client: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)}
+}
+
+func TestGetNodeGroups(t *testing.T) {
+	client := newAutoscalerClient(
+		newNodePool("general", "default", newRequirement(wrapper.LabelInstanceCategory, "In", "m", "c")),
+		newNodePool("spot", "default", newRequirement(wrapper.LabelCapacityType, "In", "spot")),
+		newNodePool("orphaned", "missing"),
+		newObject("karpenter.k8s.aws/v1", "EC2NodeClass", "", "default", map[string]interface{}{"status": newReadyStatus("True")}),
+		newMachineDeployment("workers", map[string]string{wrapper.AnnotationNodeGroupMaxSize: "10"}, "workers-spot"),
+		newMachineDeployment("scale-from-zero", map[string]string{
+			wrapper.AnnotationNodeGroupMaxSize: "5",
+			wrapper.AnnotationCapacityLabels:   "team=ml, node.kubernetes.io/instance-type=p3.2xlarge",
+		}, "missing"),
+		newMachineDeployment("unmanaged", nil, "workers-spot"),
+		newObject("infrastructure.cluster.x-k8s.io/v1beta2", "AWSMachineTemplate", "clusters", "workers-spot", map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{"instanceType": "m5.xlarge", "spotMarketOptions": map[string]interface{}{}},
+				},
+			},
+		}),
+	)
+
+	groups, err := client.GetNodeGroups(context.Background())
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, group := range groups {
+		names = append(names, group.Source+":"+group.Name)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"karpenter:general",
+		"karpenter:spot",
+		"cluster-autoscaler:clusters/workers",
+		"cluster-autoscaler:clusters/scale-from-zero",
+	}, names, "Expected node pools without a node class and unscaled machine deployments to be left out")
+
+	for _, group := range groups {
+		switch group.Name {
+		case "general":
+			assert.Equal(t, []corev1.NodeSelectorRequirement{
+				{Key: wrapper.LabelInstanceCategory, Operator: corev1.NodeSelectorOpIn, Values: []string{"m", "c"}},
+				{Key: "team", Operator: corev1.NodeSelectorOpIn, Values: []string{"data"}},
+				{Key: wrapper.LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{wrapper.CapacityTypeOnDemand}},
+			}, group.Requirements, "Expected template labels and the on-demand default as requirements")
+		case "clusters/workers":
+			assert.Equal(t, []corev1.NodeSelectorRequirement{
+				{Key: ultron.LabelInstanceType, Operator: corev1.NodeSelectorOpIn, Values: []string{"m5.xlarge"}},
+				{Key: wrapper.LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{wrapper.CapacityTypeSpot}},
+			}, group.Requirements)
+		case "clusters/scale-from-zero":
+			assert.Equal(t, []string{"p3.2xlarge"}, group.Requirements[0].Values)
+			assert.Equal(t, []string{wrapper.CapacityTypeOnDemand}, group.Requirements[1].Values)
+		}
+	}
+}
+
+func TestGetNodeGroupsSkipsNodeClassesThatAreNotReady(t *testing.T) {
+	client := newAutoscalerClient(
+		newNodePool("general", "default"),
+		newObject("karpenter.k8s.aws/v1", "EC2NodeClass", "", "default", map[string]interface{}{"status": newReadyStatus("False")}),
+	)
+
+	groups, err := client.GetNodeGroups(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func TestGetLaunchableComputeConfigurations(t *testing.T) {
+	client := newAutoscalerClient(
+		newNodePool("general", "default",
+			newRequirement(wrapper.LabelInstanceCategory, "In", "m"),
+			newRequirement(wrapper.LabelInstanceCpu, "Gt", "2"),
+			newRequirement("kubernetes.io/arch", "In", "amd64"),
+		),
+		newObject("karpenter.k8s.aws/v1", "EC2NodeClass", "", "default", map[string]interface{}{}),
+	)
+
+	configs, err := client.GetLaunchableComputeConfigurations(context.Background(), []ultron.ComputeConfiguration{
+		newConfiguration("m5.large", "us-east-1", 2, 0.096, ultron.ComputeTypeDurable),
+		newConfiguration("m5.xlarge", "us-east-1", 4, 0.192, ultron.ComputeTypeDurable),
+		newConfiguration("m5.xlarge", "us-east-1", 4, 0.07, ultron.ComputeTypeEphemeral),
+		newConfiguration("m5.xlarge", "eu-west-1", 4, 0.214, ultron.ComputeTypeDurable),
+		newConfiguration("c5.xlarge", "us-east-1", 4, 0.17, ultron.ComputeTypeDurable),
+	}, []string{"us-east-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*configs), "Expected only on-demand m-family configurations above 2 vCPUs in the cluster region")
+	assert.Equal(t, "m5.xlarge", *(*configs)[0].Identifier)
+	assert.Equal(t, "us-east-1", *(*configs)[0].Location)
+}
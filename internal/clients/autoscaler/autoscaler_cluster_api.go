@@ -0,0 +1,147 @@
+package autoscaler
+
+import (
+	"context"
+	"strings"
+
+	ultron "github.com/be-heroes/ultron/pkg"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	ClusterApiGroup = "cluster.x-k8s.io"
+
+	AnnotationNodeGroupMinSize = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"
+	AnnotationNodeGroupMaxSize = "cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"
+	AnnotationCapacityLabels   = "capacity.cluster-autoscaler.kubernetes.io/labels"
+)
+
+var (
+	ClusterApiMachineDeployment = schema.GroupVersionResource{Group: ClusterApiGroup, Version: "v1beta1", Resource: "machinedeployments"}
+	ClusterApiMachineSet        = schema.GroupVersionResource{Group: ClusterApiGroup, Version: "v1beta1", Resource: "machinesets"}
+)
+
+// getClusterApiNodeGroups reads the MachineDeployments and MachineSets the Cluster Autoscaler scales, i.e. the
+// ones with a maximum size annotation. Their instance type comes from the infrastructure machine template
+// (instanceType on AWS and GCP, vmSize on Azure), or from the scale-from-zero labels annotation when the
+// template cannot be read. Templates that request spot capacity make the node group spot.
+func (ac *AutoscalerClient) getClusterApiNodeGroups(ctx context.Context) ([]NodeGroup, error) {
+	var groups []NodeGroup
+
+	for _, resource := range []schema.GroupVersionResource{ClusterApiMachineDeployment, ClusterApiMachineSet} {
+		objects, err := ac.listResources(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			if object.GetAnnotations()[AnnotationNodeGroupMaxSize] == "" || isOwnedByMachineDeployment(&object) {
+				continue
+			}
+
+			instanceType, capacityType, err := ac.getMachineTemplateShape(ctx, &object)
+			if err != nil {
+				return nil, err
+			}
+
+			if instanceType == "" {
+				instanceType = parseLabels(object.GetAnnotations()[AnnotationCapacityLabels])[ultron.LabelInstanceType]
+			}
+
+			if instanceType == "" {
+				continue
+			}
+
+			groups = append(groups, NodeGroup{
+				Source: NodeGroupSourceClusterAutoscaler,
+				Name:   object.GetNamespace() + "/" + object.GetName(),
+				Requirements: []corev1.NodeSelectorRequirement{
+					{Key: ultron.LabelInstanceType, Operator: corev1.NodeSelectorOpIn, Values: []string{instanceType}},
+					{Key: LabelCapacityType, Operator: corev1.NodeSelectorOpIn, Values: []string{capacityType}},
+				},
+			})
+		}
+	}
+
+	return groups, nil
+}
+
+func (ac *AutoscalerClient) getMachineTemplateShape(ctx context.Context, object *unstructured.Unstructured) (string, string, error) {
+	ref, found, _ := unstructured.NestedStringMap(object.Object, "spec", "template", "spec", "infrastructureRef")
+	if !found || ref["apiVersion"] == "" || ref["kind"] == "" || ref["name"] == "" {
+		return "", CapacityTypeOnDemand, nil
+	}
+
+	groupVersion, err := schema.ParseGroupVersion(ref["apiVersion"])
+	if err != nil {
+		return "", CapacityTypeOnDemand, nil
+	}
+
+	namespace := ref["namespace"]
+	if namespace == "" {
+		namespace = object.GetNamespace()
+	}
+
+	template, err := ac.getResource(ctx, groupVersion.WithResource(toResourceName(ref["kind"])), namespace, ref["name"])
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return "", CapacityTypeOnDemand, nil
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	spec, _, _ := unstructured.NestedMap(template.Object, "spec", "template", "spec")
+
+	var instanceType string
+
+	for _, field := range []string{"instanceType", "vmSize"} {
+		if value, ok := spec[field].(string); ok && value != "" {
+			instanceType = value
+
+			break
+		}
+	}
+
+	capacityType := CapacityTypeOnDemand
+
+	_, hasAwsSpot := spec["spotMarketOptions"]
+	_, hasAzureSpot := spec["spotVMOptions"]
+	preemptible, _ := spec["preemptible"].(bool)
+	provisioningModel, _ := spec["provisioningModel"].(string)
+
+	if hasAwsSpot || hasAzureSpot || preemptible || provisioningModel == "Spot" {
+		capacityType = CapacityTypeSpot
+	}
+
+	return instanceType, capacityType, nil
+}
+
+// isOwnedByMachineDeployment skips MachineSets that a MachineDeployment manages, since the Cluster Autoscaler
+// scales the MachineDeployment.
+func isOwnedByMachineDeployment(object *unstructured.Unstructured) bool {
+	for _, owner := range object.GetOwnerReferences() {
+		if owner.Kind == "MachineDeployment" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseLabels reads the "key=value,key=value" format of the scale-from-zero labels annotation.
+func parseLabels(value string) map[string]string {
+	labels := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && key != "" {
+			labels[key] = value
+		}
+	}
+
+	return labels
+}
@@ -0,0 +1,188 @@
+package autoscaler
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	KarpenterGroup = "karpenter.sh"
+
+	LabelCapacityType = "karpenter.sh/capacity-type"
+
+	CapacityTypeOnDemand = "on-demand"
+	CapacityTypeSpot     = "spot"
+)
+
+var (
+	KarpenterNodePoolV1      = schema.GroupVersionResource{Group: KarpenterGroup, Version: "v1", Resource: "nodepools"}
+	KarpenterNodePoolV1beta1 = schema.GroupVersionResource{Group: KarpenterGroup, Version: "v1beta1", Resource: "nodepools"}
+)
+
+// getKarpenterNodeGroups reads the NodePools together with the NodeClass each of them references. A NodePool
+// whose NodeClass is missing or not ready cannot launch nodes and is left out, as is one that is not ready
+// itself. Karpenter launches on-demand capacity unless a NodePool allows spot, so that is made explicit.
+func (ac *AutoscalerClient) getKarpenterNodeGroups(ctx context.Context) ([]NodeGroup, error) {
+	nodePools, err := ac.listResources(ctx, KarpenterNodePoolV1, KarpenterNodePoolV1beta1)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []NodeGroup
+
+	for _, nodePool := range nodePools {
+		if !isReady(&nodePool) {
+			continue
+		}
+
+		launchable, err := ac.hasReadyNodeClass(ctx, &nodePool)
+		if err != nil {
+			return nil, err
+		}
+
+		if !launchable {
+			continue
+		}
+
+		requirements := getKarpenterRequirements(&nodePool)
+
+		if !hasRequirement(requirements, LabelCapacityType) {
+			requirements = append(requirements, corev1.NodeSelectorRequirement{
+				Key:      LabelCapacityType,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{CapacityTypeOnDemand},
+			})
+		}
+
+		groups = append(groups, NodeGroup{
+			Source:       NodeGroupSourceKarpenter,
+			Name:         nodePool.GetName(),
+			Requirements: requirements,
+		})
+	}
+
+	return groups, nil
+}
+
+func (ac *AutoscalerClient) hasReadyNodeClass(ctx context.Context, nodePool *unstructured.Unstructured) (bool, error) {
+	ref, found, _ := unstructured.NestedStringMap(nodePool.Object, "spec", "template", "spec", "nodeClassRef")
+	if !found || ref["kind"] == "" || ref["name"] == "" {
+		return false, nil
+	}
+
+	// v1 references name the group, v1beta1 references the API version.
+	resource := schema.GroupVersionResource{Group: ref["group"], Version: nodePool.GroupVersionKind().Version}
+	if ref["apiVersion"] != "" {
+		groupVersion, err := schema.ParseGroupVersion(ref["apiVersion"])
+		if err != nil {
+			return false, nil
+		}
+
+		resource.Group = groupVersion.Group
+		resource.Version = groupVersion.Version
+	}
+
+	resource.Resource = toResourceName(ref["kind"])
+
+	nodeClass, err := ac.getResource(ctx, resource, "", ref["name"])
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return isReady(nodeClass), nil
+}
+
+// getKarpenterRequirements reads the requirements of the NodePool template. Its labels are fixed on every node
+// it launches, so they are requirements too.
+func getKarpenterRequirements(nodePool *unstructured.Unstructured) []corev1.NodeSelectorRequirement {
+	var requirements []corev1.NodeSelectorRequirement
+
+	items, _, _ := unstructured.NestedSlice(nodePool.Object, "spec", "template", "spec", "requirements")
+
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		key, _, _ := unstructured.NestedString(fields, "key")
+		operator, _, _ := unstructured.NestedString(fields, "operator")
+		values, _, _ := unstructured.NestedStringSlice(fields, "values")
+
+		if key == "" || operator == "" {
+			continue
+		}
+
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOperator(operator),
+			Values:   values,
+		})
+	}
+
+	labels, _, _ := unstructured.NestedStringMap(nodePool.Object, "spec", "template", "metadata", "labels")
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		requirements = append(requirements, corev1.NodeSelectorRequirement{
+			Key:      key,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{labels[key]},
+		})
+	}
+
+	return requirements
+}
+
+// isReady treats an object without a Ready condition as ready, since older Karpenter releases do not set one.
+func isReady(object *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		return condition["status"] == string(corev1.ConditionTrue)
+	}
+
+	return true
+}
+
+func hasRequirement(requirements []corev1.NodeSelectorRequirement, key string) bool {
+	for _, requirement := range requirements {
+		if requirement.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toResourceName derives the resource name of a kind, e.g. EC2NodeClass becomes ec2nodeclasses.
+func toResourceName(kind string) string {
+	name := strings.ToLower(kind)
+
+	if strings.HasSuffix(name, "s") {
+		return name + "es"
+	}
+
+	return name + "s"
+}
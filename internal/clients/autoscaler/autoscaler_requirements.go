@@ -0,0 +1,136 @@
+package autoscaler
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	ultron "github.com/be-heroes/ultron/pkg"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	LabelTopologyRegion   = "topology.kubernetes.io/region"
+	LabelInstanceFamily   = "karpenter.k8s.aws/instance-family"
+	LabelInstanceCpu      = "karpenter.k8s.aws/instance-cpu"
+	LabelInstanceMemory   = "karpenter.k8s.aws/instance-memory"
+	LabelInstanceCategory = "karpenter.k8s.aws/instance-category"
+)
+
+// ExpandNodeGroups returns the configurations at least one node group may launch, in the order given. Only
+// configurations with a price are considered, since ultron cannot weigh the others.
+func ExpandNodeGroups(groups []NodeGroup, configurations []ultron.ComputeConfiguration, regions []string) []ultron.ComputeConfiguration {
+	result := []ultron.ComputeConfiguration{}
+
+	for _, configuration := range configurations {
+		if configuration.Identifier == nil || configuration.Cost == nil || configuration.Cost.PricePerUnit == nil {
+			continue
+		}
+
+		labels := GetLabels(&configuration)
+
+		for _, group := range groups {
+			if group.Allows(labels, regions) {
+				result = append(result, configuration)
+
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// GetLabels derives the node labels a node of the configuration would carry. The family, category, vCPU and
+// memory labels follow the AWS naming used by Karpenter (m5.large is family m5 and category m).
+func GetLabels(configuration *ultron.ComputeConfiguration) map[string]string {
+	labels := map[string]string{
+		ultron.LabelInstanceType: *configuration.Identifier,
+		LabelCapacityType:        CapacityTypeOnDemand,
+	}
+
+	if configuration.ComputeType == ultron.ComputeTypeEphemeral {
+		labels[LabelCapacityType] = CapacityTypeSpot
+	}
+
+	if configuration.Location != nil {
+		labels[LabelTopologyRegion] = *configuration.Location
+	}
+
+	if family, _, found := strings.Cut(*configuration.Identifier, "."); found {
+		labels[LabelInstanceFamily] = family
+
+		if index := strings.IndexFunc(family, func(r rune) bool { return r >= '0' && r <= '9' }); index > 0 {
+			labels[LabelInstanceCategory] = family[:index]
+		}
+	}
+
+	if configuration.VCpu != nil {
+		labels[LabelInstanceCpu] = strconv.FormatInt(*configuration.VCpu, 10)
+	}
+
+	if configuration.RamGb != nil {
+		labels[LabelInstanceMemory] = strconv.FormatInt(*configuration.RamGb*1024, 10)
+	}
+
+	return labels
+}
+
+// Allows checks the labels of a configuration against the requirements of the node group. Requirements on
+// labels a configuration does not describe, such as kubernetes.io/arch, cannot be checked and are ignored.
+// Without a region requirement, the node group is limited to the given regions when there are any.
+func (g *NodeGroup) Allows(labels map[string]string, regions []string) bool {
+	if len(regions) > 0 && !hasRequirement(g.Requirements, LabelTopologyRegion) {
+		if region, ok := labels[LabelTopologyRegion]; ok && !slices.Contains(regions, region) {
+			return false
+		}
+	}
+
+	for _, requirement := range g.Requirements {
+		value, ok := labels[requirement.Key]
+		if !ok {
+			continue
+		}
+
+		if !matchesRequirement(requirement, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesRequirement(requirement corev1.NodeSelectorRequirement, value string) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return true
+	case corev1.NodeSelectorOpDoesNotExist:
+		return false
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if len(requirement.Values) != 1 {
+			return false
+		}
+
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+
+		bound, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+
+		if requirement.Operator == corev1.NodeSelectorOpGt {
+			return actual > bound
+		}
+
+		return actual < bound
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package autoscaler_test
+
+import (
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/autoscaler"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newConfiguration(identifier string, location string, vCpu int64, price float64, computeType ultron.ComputeType) ultron.ComputeConfiguration {
+	ramGb := vCpu * 4
+
+	return ultron.ComputeConfiguration{
+		Identifier:  &identifier,
+		Location:    &location,
+		VCpu:        &vCpu,
+		RamGb:       &ramGb,
+		Cost:        &ultron.ComputeCost{PricePerUnit: &price},
+		ComputeType: computeType,
+	}
+}
+
+func TestGetLabels(t *testing.T) {
+	configuration := newConfiguration("c6gn.2xlarge", "us-west-2", 8, 0.3456, ultron.ComputeTypeEphemeral)
+
+	assert.Equal(t, map[string]string{
+		ultron.LabelInstanceType:      "c6gn.2xlarge",
+		wrapper.LabelCapacityType:     wrapper.CapacityTypeSpot,
+		wrapper.LabelTopologyRegion:   "us-west-2",
+		wrapper.LabelInstanceFamily:   "c6gn",
+		wrapper.LabelInstanceCategory: "c",
+		wrapper.LabelInstanceCpu:      "8",
+		wrapper.LabelInstanceMemory:   "32768",
+	}, wrapper.GetLabels(&configuration))
+}
+
+func TestNodeGroupAllows(t *testing.T) {
+	configuration := newConfiguration("m5.xlarge", "us-east-1", 4, 0.192, ultron.ComputeTypeDurable)
+	labels := wrapper.GetLabels(&configuration)
+
+	cases := []struct {
+		requirement corev1.NodeSelectorRequirement
+		allowed     bool
+	}{
+		{corev1.NodeSelectorRequirement{Key: ultron.LabelInstanceType, Operator: corev1.NodeSelectorOpIn, Values: []string{"m5.xlarge"}}, true},
+		{corev1.NodeSelectorRequirement{Key: ultron.LabelInstanceType, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"m5.xlarge"}}, false},
+		{corev1.NodeSelectorRequirement{Key: wrapper.LabelInstanceFamily, Operator: corev1.NodeSelectorOpExists}, true},
+		{corev1.NodeSelectorRequirement{Key: wrapper.LabelInstanceFamily, Operator: corev1.NodeSelectorOpDoesNotExist}, false},
+		{corev1.NodeSelectorRequirement{Key: wrapper.LabelInstanceMemory, Operator: corev1.NodeSelectorOpGt, Values: []string{"8192"}}, true},
+		{corev1.NodeSelectorRequirement{Key: wrapper.LabelInstanceCpu, Operator: corev1.NodeSelectorOpLt, Values: []string{"4"}}, false},
+		{corev1.NodeSelectorRequirement{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}, true},
+		{corev1.NodeSelectorRequirement{Key: wrapper.LabelTopologyRegion, Operator: corev1.NodeSelectorOpIn, Values: []string{"eu-west-1"}}, false},
+	}
+
+	for _, c := range cases {
+		group := wrapper.NodeGroup{Requirements: []corev1.NodeSelectorRequirement{c.requirement}}
+		assert.Equal(t, c.allowed, group.Allows(labels, nil), "%+v", c.requirement)
+	}
+
+	group := wrapper.NodeGroup{}
+	assert.True(t, group.Allows(labels, []string{"us-east-1"}))
+	assert.False(t, group.Allows(labels, []string{"eu-west-1"}), "Expected the cluster regions to apply without a region requirement")
+
+	group = wrapper.NodeGroup{Requirements: []corev1.NodeSelectorRequirement{
+		{Key: wrapper.LabelTopologyRegion, Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east-1"}},
+	}}
+	assert.True(t, group.Allows(labels, []string{"eu-west-1"}), "Expected a region requirement to override the cluster regions")
+}
+
+func TestExpandNodeGroupsSkipsUnpricedConfigurations(t *testing.T) {
+	priced := newConfiguration("m5.xlarge", "us-east-1", 4, 0.192, ultron.ComputeTypeDurable)
+	unpriced := newConfiguration("m5.large", "us-east-1", 2, 0, ultron.ComputeTypeDurable)
+	unpriced.Cost = nil
+
+	result := wrapper.ExpandNodeGroups([]wrapper.NodeGroup{{}}, []ultron.ComputeConfiguration{priced, unpriced}, nil)
+	assert.Equal(t, []ultron.ComputeConfiguration{priced}, result)
+
+	assert.Empty(t, wrapper.ExpandNodeGroups(nil, []ultron.ComputeConfiguration{priced}, nil), "Expected nothing to be launchable without node groups")
+}
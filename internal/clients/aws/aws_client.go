@@ -3,27 +3,116 @@ package aws
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
 	pricingTypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
 	ultron "github.com/be-heroes/ultron/pkg"
 )
 
+const DefaultCurrency = "USD"
+
+type AwsPriceListItem struct {
+	Product AwsProduct `json:"product"`
+	Terms   AwsTerms   `json:"terms"`
+}
+
+type AwsProduct struct {
+	ProductFamily string            `json:"productFamily"`
+	Sku           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+}
+
+type AwsTerms struct {
+	OnDemand map[string]AwsTerm `json:"OnDemand"`
+	Reserved map[string]AwsTerm `json:"Reserved"`
+}
+
+type AwsTerm struct {
+	OfferTermCode   string                       `json:"offerTermCode"`
+	Sku             string                       `json:"sku"`
+	EffectiveDate   string                       `json:"effectiveDate"`
+	PriceDimensions map[string]AwsPriceDimension `json:"priceDimensions"`
+	TermAttributes  map[string]string            `json:"termAttributes"`
+}
+
+type AwsPriceDimension struct {
+	RateCode     string            `json:"rateCode"`
+	Description  string            `json:"description"`
+	BeginRange   string            `json:"beginRange"`
+	EndRange     string            `json:"endRange"`
+	Unit         string            `json:"unit"`
+	PricePerUnit map[string]string `json:"pricePerUnit"`
+	AppliesTo    []string          `json:"appliesTo"`
+}
+
+// AwsReservedCost is a Reserved Instance offer with its upfront fee amortized over the lease, so that
+// EffectiveHourlyRate can be compared directly with on-demand and spot hourly rates.
+type AwsReservedCost struct {
+	InstanceType        string
+	Location            string
+	OfferTermCode       string
+	LeaseContractLength string
+	LeaseYears          int
+	OfferingClass       string
+	PurchaseOption      string
+	Currency            string
+	UpfrontFee          float64
+	HourlyRate          float64
+	EffectiveHourlyRate float64
+}
+
+// AwsPrice is a parsed price dimension with a normalized unit, a numeric range and a price for every currency it lists.
+type AwsPrice struct {
+	RateCode     string
+	Description  string
+	Unit         string
+	BeginRange   float64
+	EndRange     float64
+	PricePerUnit map[string]float64
+}
+
 type IAwsClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
+	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetComputeConfigurations(ctx context.Context, regionCode string) (*[]ultron.ComputeConfiguration, error)
+	GetSpotComputeConfigurations(ctx context.Context, regionCode string) (*[]ultron.ComputeConfiguration, error)
 	GetComputeCost(ctx context.Context, instanceType, region string) (*[]ultron.ComputeCost, error)
+	GetComputeCostByRegionCode(ctx context.Context, instanceType, regionCode string) (*[]ultron.ComputeCost, error)
+	GetReservedComputeCost(ctx context.Context, instanceType, region string) (*[]AwsReservedCost, error)
+	GetReservedComputeCosts(ctx context.Context) (*[]attendant.ReservedComputeCost, error)
 }
 
 type IPricingAPI interface {
 	GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error)
 }
 
-// TODO: Refactor client to return compute configs, as well as compute costs and adhere to the same interface as the emma & wisp clients
+type IEc2API interface {
+	DescribeSpotPriceHistory(ctx context.Context, params *ec2.DescribeSpotPriceHistoryInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSpotPriceHistoryOutput, error)
+}
+
 type AwsClient struct {
 	config        aws.Config
 	PricingClient IPricingAPI
+	Ec2Client     IEc2API
+	RegionCatalog *AwsRegionCatalog
+	Regions       []string
+	priceLists    attendant.FetchCache[[]AwsPriceListItem]
 }
 
 func NewAwsClient(region string) (*AwsClient, error) {
@@ -37,16 +126,523 @@ func NewAwsClient(region string) (*AwsClient, error) {
 		return nil, err
 	}
 
+	regionCatalog, err := NewAwsRegionCatalog()
+	if err != nil {
+		return nil, err
+	}
+
 	pricingClient := pricing.NewFromConfig(pricingCfg)
+	ec2Client := ec2.NewFromConfig(awsCfg)
 
 	return &AwsClient{
 		config:        awsCfg,
 		PricingClient: pricingClient,
+		Ec2Client:     ec2Client,
+		RegionCatalog: regionCatalog,
+		Regions:       []string{region},
 	}, nil
 }
 
+func (c *AwsClient) GetName() string {
+	return attendant.ProviderNameAws
+}
+
+func (c *AwsClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+}
+
+func (c *AwsClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	durableConfigs, err := c.GetDurableComputeConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralConfigs, err := c.GetEphemeralComputeConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append(*durableConfigs, *ephemeralConfigs...)
+
+	return &result, nil
+}
+
+func (c *AwsClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	result := []ultron.ComputeConfiguration{}
+
+	for _, region := range c.Regions {
+		configs, err := c.GetComputeConfigurations(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *configs...)
+	}
+
+	return &result, nil
+}
+
+func (c *AwsClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	result := []ultron.ComputeConfiguration{}
+
+	for _, region := range c.Regions {
+		configs, err := c.GetSpotComputeConfigurations(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *configs...)
+	}
+
+	return &result, nil
+}
+
+func (c *AwsClient) GetComputeConfigurations(ctx context.Context, regionCode string) (*[]ultron.ComputeConfiguration, error) {
+	items, err := c.getRegionPriceListItems(ctx, regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ultron.ComputeConfiguration{}
+
+	for _, item := range items {
+		if item.Product.Attributes["instanceType"] == "" {
+			continue
+		}
+
+		config, err := c.mapConfiguration(&item, ultron.ComputeTypeDurable)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, config)
+	}
+
+	return &results, nil
+}
+
+// GetSpotComputeConfigurations prices the instance types of the regional price list with their latest spot
+// price in every availability zone. The price list is shared with GetComputeConfigurations, so a refresh
+// cycle downloads it once per region.
+func (c *AwsClient) GetSpotComputeConfigurations(ctx context.Context, regionCode string) (*[]ultron.ComputeConfiguration, error) {
+	durableConfigs, err := c.GetComputeConfigurations(ctx, regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceTypes := make(map[string]ultron.ComputeConfiguration)
+
+	for _, config := range *durableConfigs {
+		instanceTypes[*config.Identifier] = config
+	}
+
+	spotPrices, err := c.getSpotPrices(ctx, regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ultron.ComputeConfiguration{}
+
+	for _, spotPrice := range spotPrices {
+		config, ok := instanceTypes[string(spotPrice.InstanceType)]
+		if !ok {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(aws.ToString(spotPrice.SpotPrice), 64)
+		if err != nil {
+			return nil, err
+		}
+
+		priceUnit := attendant.CostUnitHours
+		priceCurrency := DefaultCurrency
+
+		config.DataCenter = spotPrice.AvailabilityZone
+		config.Cost = &ultron.ComputeCost{
+			Unit:         &priceUnit,
+			Currency:     &priceCurrency,
+			PricePerUnit: &price,
+		}
+		config.ComputeType = ultron.ComputeTypeEphemeral
+
+		results = append(results, config)
+	}
+
+	return &results, nil
+}
+
 func (c *AwsClient) GetComputeCost(ctx context.Context, instanceType, region string) (*[]ultron.ComputeCost, error) {
-	input := &pricing.GetProductsInput{
+	items, err := c.getPriceListItems(ctx, getComputeCostInput(instanceType, region))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ultron.ComputeCost
+
+	for _, item := range items {
+		costs, err := c.mapOnDemandCosts(&item)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, costs...)
+	}
+
+	return &results, nil
+}
+
+func (c *AwsClient) GetComputeCostByRegionCode(ctx context.Context, instanceType, regionCode string) (*[]ultron.ComputeCost, error) {
+	if c.RegionCatalog == nil {
+		return nil, fmt.Errorf("region catalog is not configured")
+	}
+
+	location, err := c.RegionCatalog.GetPricingLocation(regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetComputeCost(ctx, instanceType, location)
+}
+
+func (c *AwsClient) GetReservedComputeCost(ctx context.Context, instanceType, region string) (*[]AwsReservedCost, error) {
+	items, err := c.getPriceListItems(ctx, getComputeCostInput(instanceType, region))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []AwsReservedCost{}
+
+	for _, item := range items {
+		costs, err := c.mapReservedCosts(&item)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, costs...)
+	}
+
+	return &results, nil
+}
+
+// GetReservedComputeCosts returns the amortized Reserved Instance prices of every instance type in Regions. The
+// Reserved terms come with the regional price lists the configurations are built from.
+func (c *AwsClient) GetReservedComputeCosts(ctx context.Context) (*[]attendant.ReservedComputeCost, error) {
+	results := []attendant.ReservedComputeCost{}
+
+	for _, regionCode := range c.Regions {
+		items, err := c.getRegionPriceListItems(ctx, regionCode)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			if item.Product.Attributes["instanceType"] == "" {
+				continue
+			}
+
+			reservedCosts, err := c.mapReservedCosts(&item)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, reservedCost := range reservedCosts {
+				results = append(results, attendant.ReservedComputeCost{
+					Provider:            attendant.ProviderNameAws,
+					Identifier:          reservedCost.InstanceType,
+					Location:            regionCode,
+					TermYears:           reservedCost.LeaseYears,
+					OfferingClass:       reservedCost.OfferingClass,
+					PurchaseOption:      reservedCost.PurchaseOption,
+					Currency:            reservedCost.Currency,
+					UpfrontFee:          reservedCost.UpfrontFee,
+					HourlyRate:          reservedCost.HourlyRate,
+					EffectiveHourlyRate: reservedCost.EffectiveHourlyRate,
+				})
+			}
+		}
+	}
+
+	return &results, nil
+}
+
+// getRegionPriceListItems downloads the Linux compute instance price list of a region, which holds the
+// OnDemand and Reserved terms of every instance type, at most once per refresh cycle.
+func (c *AwsClient) getRegionPriceListItems(ctx context.Context, regionCode string) ([]AwsPriceListItem, error) {
+	return c.priceLists.Get(ctx, regionCode, attendant.DefaultFetchCacheTtl, func(ctx context.Context) ([]AwsPriceListItem, error) {
+		input := &pricing.GetProductsInput{
+			ServiceCode: aws.String("AmazonEC2"),
+			Filters: []pricingTypes.Filter{
+				{
+					Type:  pricingTypes.FilterTypeTermMatch,
+					Field: aws.String("regionCode"),
+					Value: aws.String(regionCode),
+				},
+				{
+					Type:  pricingTypes.FilterTypeTermMatch,
+					Field: aws.String("productFamily"),
+					Value: aws.String("Compute Instance"),
+				},
+				{
+					Type:  pricingTypes.FilterTypeTermMatch,
+					Field: aws.String("operatingSystem"),
+					Value: aws.String("Linux"),
+				},
+				{
+					Type:  pricingTypes.FilterTypeTermMatch,
+					Field: aws.String("preInstalledSw"),
+					Value: aws.String("NA"),
+				},
+				{
+					Type:  pricingTypes.FilterTypeTermMatch,
+					Field: aws.String("tenancy"),
+					Value: aws.String("Shared"),
+				},
+				{
+					Type:  pricingTypes.FilterTypeTermMatch,
+					Field: aws.String("capacitystatus"),
+					Value: aws.String("Used"),
+				},
+			},
+		}
+
+		return c.getPriceListItems(ctx, input)
+	})
+}
+
+func (c *AwsClient) getPriceListItems(ctx context.Context, input *pricing.GetProductsInput) ([]AwsPriceListItem, error) {
+	var items []AwsPriceListItem
+
+	paginator := pricing.NewGetProductsPaginator(c.PricingClient, input)
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, priceItem := range output.PriceList {
+			var item AwsPriceListItem
+			if err := json.Unmarshal([]byte(priceItem), &item); err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// getSpotPrices returns the most recent Linux spot price for every instance type and availability zone in a region.
+func (c *AwsClient) getSpotPrices(ctx context.Context, regionCode string) ([]ec2Types.SpotPrice, error) {
+	input := &ec2.DescribeSpotPriceHistoryInput{
+		StartTime:           aws.Time(time.Now()),
+		ProductDescriptions: []string{"Linux/UNIX"},
+	}
+
+	latest := make(map[string]ec2Types.SpotPrice)
+	var keys []string
+
+	paginator := ec2.NewDescribeSpotPriceHistoryPaginator(c.Ec2Client, input)
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx, func(o *ec2.Options) {
+			o.Region = regionCode
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, spotPrice := range output.SpotPriceHistory {
+			key := string(spotPrice.InstanceType) + "/" + aws.ToString(spotPrice.AvailabilityZone)
+
+			current, ok := latest[key]
+			if !ok {
+				keys = append(keys, key)
+			} else if current.Timestamp != nil && spotPrice.Timestamp != nil && !spotPrice.Timestamp.After(*current.Timestamp) {
+				continue
+			}
+
+			latest[key] = spotPrice
+		}
+	}
+
+	results := make([]ec2Types.SpotPrice, 0, len(keys))
+
+	for _, key := range keys {
+		results = append(results, latest[key])
+	}
+
+	return results, nil
+}
+
+// mapOnDemandCosts returns the costs of every OnDemand term, ordered by term and rate code so that the order
+// does not change between refreshes.
+func (c *AwsClient) mapOnDemandCosts(item *AwsPriceListItem) ([]ultron.ComputeCost, error) {
+	var results []ultron.ComputeCost
+
+	for _, termKey := range slices.Sorted(maps.Keys(item.Terms.OnDemand)) {
+		term := item.Terms.OnDemand[termKey]
+
+		for _, dimensionKey := range slices.Sorted(maps.Keys(term.PriceDimensions)) {
+			priceDimension := term.PriceDimensions[dimensionKey]
+
+			price, err := priceDimension.Parse()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, currency := range price.GetCurrencies() {
+				priceUnit := price.Unit
+				priceCurrency := currency
+				pricePerUnit := price.PricePerUnit[currency]
+
+				results = append(results, ultron.ComputeCost{
+					Unit:         &priceUnit,
+					Currency:     &priceCurrency,
+					PricePerUnit: &pricePerUnit,
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// mapReservedCosts parses every Reserved term of a price list item, ordered by term code. A term carries an upfront fee
+// dimension ("Quantity") and an hourly dimension ("Hrs"), either of which may be zero depending on the purchase option.
+func (c *AwsClient) mapReservedCosts(item *AwsPriceListItem) ([]AwsReservedCost, error) {
+	var results []AwsReservedCost
+
+	for _, termKey := range slices.Sorted(maps.Keys(item.Terms.Reserved)) {
+		term := item.Terms.Reserved[termKey]
+
+		leaseYears, err := parseLeaseContractLength(term.TermAttributes["LeaseContractLength"])
+		if err != nil {
+			return nil, err
+		}
+
+		reservedCost := AwsReservedCost{
+			InstanceType:        item.Product.Attributes["instanceType"],
+			Location:            item.Product.Attributes["location"],
+			OfferTermCode:       term.OfferTermCode,
+			LeaseContractLength: term.TermAttributes["LeaseContractLength"],
+			LeaseYears:          leaseYears,
+			OfferingClass:       term.TermAttributes["OfferingClass"],
+			PurchaseOption:      term.TermAttributes["PurchaseOption"],
+			Currency:            DefaultCurrency,
+		}
+
+		for _, dimensionKey := range slices.Sorted(maps.Keys(term.PriceDimensions)) {
+			priceDimension := term.PriceDimensions[dimensionKey]
+
+			price, err := priceDimension.Parse()
+			if err != nil {
+				return nil, err
+			}
+
+			currencies := price.GetCurrencies()
+			if len(currencies) == 0 {
+				continue
+			}
+
+			reservedCost.Currency = currencies[0]
+
+			if price.Unit == attendant.CostUnitQuantity {
+				reservedCost.UpfrontFee += price.PricePerUnit[reservedCost.Currency]
+			} else {
+				reservedCost.HourlyRate += price.PricePerUnit[reservedCost.Currency]
+			}
+		}
+
+		reservedCost.EffectiveHourlyRate = amortizeHourlyRate(reservedCost.UpfrontFee, reservedCost.HourlyRate, leaseYears)
+
+		results = append(results, reservedCost)
+	}
+
+	return results, nil
+}
+
+func (c *AwsClient) mapConfiguration(item *AwsPriceListItem, computeType ultron.ComputeType) (ultron.ComputeConfiguration, error) {
+	attributes := item.Product.Attributes
+	provider := attendant.ProviderNameAws
+
+	config := ultron.ComputeConfiguration{
+		Identifier:  toStringPointer(attributes["instanceType"]),
+		Provider:    &provider,
+		Location:    toStringPointer(attributes["regionCode"]),
+		OsType:      toStringPointer(attributes["operatingSystem"]),
+		VCpuType:    toStringPointer(attributes["physicalProcessor"]),
+		VCpu:        parseCount(attributes["vcpu"]),
+		RamGb:       parseMemoryGb(attributes["memory"]),
+		ComputeType: computeType,
+	}
+
+	config.VolumeGb, config.VolumeType = parseStorage(attributes["storage"])
+
+	costs, err := c.mapOnDemandCosts(item)
+	if err != nil {
+		return config, err
+	}
+
+	config.Cost = selectHourlyCost(costs)
+
+	return config, nil
+}
+
+func (d *AwsPriceDimension) Parse() (*AwsPrice, error) {
+	beginRange, err := parseRange(d.BeginRange, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	endRange, err := parseRange(d.EndRange, math.Inf(1))
+	if err != nil {
+		return nil, err
+	}
+
+	price := &AwsPrice{
+		RateCode:     d.RateCode,
+		Description:  d.Description,
+		Unit:         attendant.NormalizeCostUnit(d.Unit),
+		BeginRange:   beginRange,
+		EndRange:     endRange,
+		PricePerUnit: make(map[string]float64),
+	}
+
+	for currency, value := range d.PricePerUnit {
+		pricePerUnit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		price.PricePerUnit[strings.ToUpper(currency)] = pricePerUnit
+	}
+
+	return price, nil
+}
+
+// GetCurrencies returns the currencies the price is listed in, with DefaultCurrency first and the rest sorted.
+func (p *AwsPrice) GetCurrencies() []string {
+	var currencies []string
+
+	for currency := range p.PricePerUnit {
+		if currency != DefaultCurrency {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	sort.Strings(currencies)
+
+	if _, ok := p.PricePerUnit[DefaultCurrency]; ok {
+		currencies = append([]string{DefaultCurrency}, currencies...)
+	}
+
+	return currencies
+}
+
+func getComputeCostInput(instanceType, location string) *pricing.GetProductsInput {
+	return &pricing.GetProductsInput{
 		ServiceCode: aws.String("AmazonEC2"),
 		Filters: []pricingTypes.Filter{
 			{
@@ -57,7 +653,7 @@ func (c *AwsClient) GetComputeCost(ctx context.Context, instanceType, region str
 			{
 				Type:  pricingTypes.FilterTypeTermMatch,
 				Field: aws.String("location"),
-				Value: aws.String(region),
+				Value: aws.String(location),
 			},
 			{
 				Type:  pricingTypes.FilterTypeTermMatch,
@@ -76,70 +672,132 @@ func (c *AwsClient) GetComputeCost(ctx context.Context, instanceType, region str
 			},
 		},
 	}
+}
 
-	var results []ultron.ComputeCost
+// selectHourlyCost picks the hourly cost in DefaultCurrency, falling back to the first hourly cost and then to
+// the first cost, since an item may list fees per quantity next to its hourly rate.
+func selectHourlyCost(costs []ultron.ComputeCost) *ultron.ComputeCost {
+	if len(costs) == 0 {
+		return nil
+	}
 
-	paginator := pricing.NewGetProductsPaginator(c.PricingClient, input)
+	var hourlyCost *ultron.ComputeCost
 
-	for paginator.HasMorePages() {
-		output, err := paginator.NextPage(ctx)
-		if err != nil {
-			return nil, err
+	for i := range costs {
+		if *costs[i].Unit != attendant.CostUnitHours {
+			continue
 		}
 
-		for _, priceItem := range output.PriceList {
-			var priceMap map[string]interface{}
-			if err := json.Unmarshal([]byte(priceItem), &priceMap); err != nil {
-				return nil, err
-			}
+		if *costs[i].Currency == DefaultCurrency {
+			return &costs[i]
+		}
 
-			terms, ok := priceMap["terms"].(map[string]interface{})
-			if !ok {
-				continue
-			}
+		if hourlyCost == nil {
+			hourlyCost = &costs[i]
+		}
+	}
 
-			onDemand, ok := terms["OnDemand"].(map[string]interface{})
-			if !ok {
-				continue
-			}
+	if hourlyCost != nil {
+		return hourlyCost
+	}
 
-			for _, term := range onDemand {
-				termAttributes, ok := term.(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				priceDimensions, ok := termAttributes["priceDimensions"].(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				for _, priceDimension := range priceDimensions {
-					priceAttrs, ok := priceDimension.(map[string]interface{})
-					if !ok {
-						continue
-					}
-					pricePerUnit, ok := priceAttrs["pricePerUnit"].(map[string]interface{})
-					if !ok {
-						continue
-					}
-					priceUSD, _ := pricePerUnit["USD"].(string)
-					priceUSDValue, err := strconv.ParseFloat(priceUSD, 32)
-					if err != nil {
-						return nil, err
-					}
-					priceCurrency := "USD"
-					priceUnit := "MONTHLY"
-
-					results = append(results, ultron.ComputeCost{
-						Unit:         &priceUnit,
-						Currency:     &priceCurrency,
-						PricePerUnit: &priceUSDValue,
-					})
-				}
-			}
-		}
+	return &costs[0]
+}
+
+// amortizeHourlyRate spreads an upfront fee evenly over every hour of the lease and adds the recurring hourly rate.
+func amortizeHourlyRate(upfrontFee float64, hourlyRate float64, leaseYears int) float64 {
+	if leaseYears <= 0 {
+		return hourlyRate
 	}
 
-	return &results, nil
+	return upfrontFee/float64(leaseYears*attendant.HoursPerYear) + hourlyRate
+}
+
+func parseLeaseContractLength(value string) (int, error) {
+	years, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "yr"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid lease contract length: %q", value)
+	}
+
+	return years, nil
+}
+
+func parseRange(value string, defaultValue float64) (float64, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	if strings.EqualFold(value, "Inf") {
+		return math.Inf(1), nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+func toStringPointer(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+func parseCount(value string) *int64 {
+	count, err := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &count
+}
+
+// parseMemoryGb parses price list memory attributes such as "16 GiB" or "1,952 GiB", rounding fractional values to the nearest GiB.
+func parseMemoryGb(value string) *int64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	memory, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+	if err != nil {
+		return nil
+	}
+
+	memoryGb := int64(math.Round(memory))
+
+	return &memoryGb
+}
+
+// parseStorage parses price list storage attributes such as "2 x 1900 NVMe SSD" into a total size and a volume type.
+// Instances without local storage ("EBS only") report no size and an EBS volume type.
+func parseStorage(value string) (*int64, *string) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if strings.EqualFold(value, "EBS only") {
+		volumeType := "EBS"
+
+		return nil, &volumeType
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) < 3 || !strings.EqualFold(fields[1], "x") {
+		return nil, nil
+	}
+
+	count, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	size, err := strconv.ParseFloat(strings.ReplaceAll(fields[2], ",", ""), 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	volumeGb := int64(math.Round(count * size))
+	volumeType := strings.Join(fields[3:], " ")
+
+	return &volumeGb, toStringPointer(volumeType)
 }
@@ -4,13 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
 	pricingTypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
 	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/aws"
 	mocks "github.com/be-heroes/ultron-attendant/mocks"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -105,3 +111,608 @@ func TestGetNodePriceData_Error(t *testing.T) {
 
 	mockPricingClient.AssertExpectations(t)
 }
+
+func TestGetComputeConfigurations_Success(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	priceItems := []map[string]interface{}{
+		{
+			"product": map[string]interface{}{
+				"productFamily": "Compute Instance",
+				"attributes": map[string]interface{}{
+					"instanceType":      "m5d.xlarge",
+					"instanceFamily":    "General purpose",
+					"location":          "US East (N. Virginia)",
+					"regionCode":        "us-east-1",
+					"operatingSystem":   "Linux",
+					"physicalProcessor": "Intel Xeon Platinum 8175",
+					"vcpu":              "4",
+					"memory":            "16 GiB",
+					"storage":           "1 x 150 NVMe SSD",
+				},
+			},
+			"terms": map[string]interface{}{
+				"OnDemand": map[string]interface{}{
+					"XYZ": map[string]interface{}{
+						"priceDimensions": map[string]interface{}{
+							"ABC": map[string]interface{}{
+								"unit": "Hrs",
+								"pricePerUnit": map[string]interface{}{
+									"USD": "0.226",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"product": map[string]interface{}{
+				"productFamily": "Compute Instance",
+				"attributes": map[string]interface{}{
+					"instanceType":      "t3.nano",
+					"location":          "US East (N. Virginia)",
+					"regionCode":        "us-east-1",
+					"operatingSystem":   "Linux",
+					"physicalProcessor": "Intel Skylake E5 2686 v5",
+					"vcpu":              "2",
+					"memory":            "0.5 GiB",
+					"storage":           "EBS only",
+				},
+			},
+			"terms": map[string]interface{}{},
+		},
+	}
+
+	var priceList []string
+
+	for _, priceItem := range priceItems {
+		priceItemJSON, _ := json.Marshal(priceItem)
+		priceList = append(priceList, string(priceItemJSON))
+	}
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.MatchedBy(func(input *pricing.GetProductsInput) bool {
+		return *input.ServiceCode == "AmazonEC2" && *input.Filters[0].Field == "regionCode" && *input.Filters[0].Value == "us-east-1"
+	}), mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: priceList,
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+		Regions:       []string{"us-east-1"},
+	}
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*configs))
+
+	config := (*configs)[0]
+	assert.Equal(t, "m5d.xlarge", *config.Identifier)
+	assert.Equal(t, "aws", *config.Provider)
+	assert.Equal(t, "us-east-1", *config.Location)
+	assert.Nil(t, config.DataCenter, "Expected on-demand configurations not to name an availability zone")
+	assert.Equal(t, "Intel Xeon Platinum 8175", *config.VCpuType)
+	assert.Equal(t, int64(4), *config.VCpu)
+	assert.Equal(t, int64(16), *config.RamGb)
+	assert.Equal(t, int64(150), *config.VolumeGb)
+	assert.Equal(t, "NVMe SSD", *config.VolumeType)
+	assert.Equal(t, ultron.ComputeTypeDurable, config.ComputeType)
+	assert.NotNil(t, config.Cost)
+	assert.Equal(t, 0.226, *config.Cost.PricePerUnit)
+	assert.Equal(t, attendant.CostUnitHours, *config.Cost.Unit)
+
+	config = (*configs)[1]
+	assert.Equal(t, "t3.nano", *config.Identifier)
+	assert.Equal(t, int64(1), *config.RamGb)
+	assert.Nil(t, config.VolumeGb)
+	assert.Equal(t, "EBS", *config.VolumeType)
+	assert.Nil(t, config.Cost)
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestGetComputeConfigurations_SelectsHourlyCost(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	priceItem := map[string]interface{}{
+		"product": map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"instanceType": "m5.large",
+				"regionCode":   "us-east-1",
+			},
+		},
+		"terms": map[string]interface{}{
+			"OnDemand": map[string]interface{}{
+				"XYZ": map[string]interface{}{
+					"priceDimensions": map[string]interface{}{
+						"AAA": map[string]interface{}{
+							"unit": "Quantity",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "12.5",
+							},
+						},
+						"BBB": map[string]interface{}{
+							"unit": "Hrs",
+							"pricePerUnit": map[string]interface{}{
+								"CNY": "0.7",
+								"USD": "0.096",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+	}
+
+	configs, err := client.GetComputeConfigurations(context.Background(), "us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*configs))
+
+	cost := (*configs)[0].Cost
+	assert.Equal(t, attendant.CostUnitHours, *cost.Unit)
+	assert.Equal(t, "USD", *cost.Currency)
+	assert.Equal(t, 0.096, *cost.PricePerUnit)
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestGetComputeConfigurations_Error(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("AWS Pricing API error"))
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+	}
+
+	_, err := client.GetComputeConfigurations(context.Background(), "us-east-1")
+	assert.EqualError(t, err, "AWS Pricing API error")
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestGetSpotComputeConfigurations_Success(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+	mockEc2Client := new(mocks.IEc2API)
+
+	priceItem := map[string]interface{}{
+		"product": map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"instanceType": "m5.large",
+				"regionCode":   "us-east-1",
+				"vcpu":         "2",
+				"memory":       "8 GiB",
+				"storage":      "EBS only",
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil)
+
+	now := time.Now()
+
+	mockEc2Client.On("DescribeSpotPriceHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&ec2.DescribeSpotPriceHistoryOutput{
+			SpotPriceHistory: []ec2Types.SpotPrice{
+				{
+					AvailabilityZone: aws.String("us-east-1a"),
+					InstanceType:     ec2Types.InstanceTypeM5Large,
+					SpotPrice:        aws.String("0.0400"),
+					Timestamp:        aws.Time(now),
+				},
+				{
+					AvailabilityZone: aws.String("us-east-1a"),
+					InstanceType:     ec2Types.InstanceTypeM5Large,
+					SpotPrice:        aws.String("0.0500"),
+					Timestamp:        aws.Time(now.Add(-time.Hour)),
+				},
+				{
+					AvailabilityZone: aws.String("us-east-1b"),
+					InstanceType:     ec2Types.InstanceTypeM5Large,
+					SpotPrice:        aws.String("0.0350"),
+					Timestamp:        aws.Time(now),
+				},
+				{
+					AvailabilityZone: aws.String("us-east-1a"),
+					InstanceType:     ec2Types.InstanceTypeP4d24xlarge,
+					SpotPrice:        aws.String("9.8300"),
+					Timestamp:        aws.Time(now),
+				},
+			},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+		Ec2Client:     mockEc2Client,
+		Regions:       []string{"us-east-1"},
+	}
+
+	configs, err := client.GetEphemeralComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*configs))
+
+	config := (*configs)[0]
+	assert.Equal(t, "m5.large", *config.Identifier)
+	assert.Equal(t, "us-east-1", *config.Location)
+	assert.Equal(t, "us-east-1a", *config.DataCenter)
+	assert.Equal(t, int64(2), *config.VCpu)
+	assert.Equal(t, int64(8), *config.RamGb)
+	assert.Equal(t, ultron.ComputeTypeEphemeral, config.ComputeType)
+	assert.Equal(t, 0.04, *config.Cost.PricePerUnit)
+
+	config = (*configs)[1]
+	assert.Equal(t, "us-east-1b", *config.DataCenter)
+	assert.Equal(t, 0.035, *config.Cost.PricePerUnit)
+
+	mockPricingClient.AssertExpectations(t)
+	mockEc2Client.AssertExpectations(t)
+}
+
+func TestGetAllComputeConfigurations_FetchesPriceListOncePerRegion(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+	mockEc2Client := new(mocks.IEc2API)
+
+	priceItem := map[string]interface{}{
+		"product": map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"instanceType": "m5.large",
+				"regionCode":   "us-east-1",
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil).
+		Once()
+
+	mockEc2Client.On("DescribeSpotPriceHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&ec2.DescribeSpotPriceHistoryOutput{
+			SpotPriceHistory: []ec2Types.SpotPrice{
+				{
+					AvailabilityZone: aws.String("us-east-1a"),
+					InstanceType:     ec2Types.InstanceTypeM5Large,
+					SpotPrice:        aws.String("0.0400"),
+				},
+			},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+		Ec2Client:     mockEc2Client,
+		Regions:       []string{"us-east-1"},
+	}
+
+	configs, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*configs))
+
+	mockPricingClient.AssertExpectations(t)
+	mockEc2Client.AssertExpectations(t)
+}
+
+func TestGetSpotComputeConfigurations_Error(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+	mockEc2Client := new(mocks.IEc2API)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{}, nil)
+	mockEc2Client.On("DescribeSpotPriceHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("AWS EC2 API error"))
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+		Ec2Client:     mockEc2Client,
+	}
+
+	_, err := client.GetSpotComputeConfigurations(context.Background(), "us-east-1")
+	assert.EqualError(t, err, "AWS EC2 API error")
+
+	mockEc2Client.AssertExpectations(t)
+}
+
+func TestGetReservedComputeCost_Success(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	priceItem := map[string]interface{}{
+		"product": map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"instanceType": "m5.large",
+				"location":     "US East (N. Virginia)",
+			},
+		},
+		"terms": map[string]interface{}{
+			"OnDemand": map[string]interface{}{
+				"JRTCKXETXF": map[string]interface{}{
+					"priceDimensions": map[string]interface{}{
+						"6YS6EN2CT7": map[string]interface{}{
+							"unit": "Hrs",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "0.0960000000",
+							},
+						},
+					},
+				},
+			},
+			"Reserved": map[string]interface{}{
+				"4NA7Y494T4": map[string]interface{}{
+					"offerTermCode": "4NA7Y494T4",
+					"priceDimensions": map[string]interface{}{
+						"2TG2D8R56U": map[string]interface{}{
+							"unit": "Hrs",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "0.0600000000",
+							},
+						},
+					},
+					"termAttributes": map[string]interface{}{
+						"LeaseContractLength": "1yr",
+						"OfferingClass":       "standard",
+						"PurchaseOption":      "No Upfront",
+					},
+				},
+				"NQ3QZPMQV9": map[string]interface{}{
+					"offerTermCode": "NQ3QZPMQV9",
+					"priceDimensions": map[string]interface{}{
+						"2TG2D8R56U": map[string]interface{}{
+							"unit": "Quantity",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "1051",
+							},
+						},
+						"6YS6EN2CT7": map[string]interface{}{
+							"unit": "Hrs",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "0.0000000000",
+							},
+						},
+					},
+					"termAttributes": map[string]interface{}{
+						"LeaseContractLength": "3yr",
+						"OfferingClass":       "standard",
+						"PurchaseOption":      "All Upfront",
+					},
+				},
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+	}
+
+	reservedCosts, err := client.GetReservedComputeCost(context.Background(), "m5.large", "US East (N. Virginia)")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*reservedCosts))
+
+	costs := make(map[string]wrapper.AwsReservedCost)
+
+	for _, cost := range *reservedCosts {
+		costs[cost.OfferTermCode] = cost
+	}
+
+	noUpfront := costs["4NA7Y494T4"]
+	assert.Equal(t, "m5.large", noUpfront.InstanceType)
+	assert.Equal(t, 1, noUpfront.LeaseYears)
+	assert.Equal(t, "No Upfront", noUpfront.PurchaseOption)
+	assert.Equal(t, 0.0, noUpfront.UpfrontFee)
+	assert.Equal(t, 0.06, noUpfront.HourlyRate)
+	assert.Equal(t, 0.06, noUpfront.EffectiveHourlyRate)
+
+	allUpfront := costs["NQ3QZPMQV9"]
+	assert.Equal(t, 3, allUpfront.LeaseYears)
+	assert.Equal(t, "All Upfront", allUpfront.PurchaseOption)
+	assert.Equal(t, 1051.0, allUpfront.UpfrontFee)
+	assert.Equal(t, 0.0, allUpfront.HourlyRate)
+	assert.InDelta(t, 1051.0/(3*attendant.HoursPerYear), allUpfront.EffectiveHourlyRate, 0.000001)
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestGetReservedComputeCost_InvalidLeaseContractLength(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	priceItem := map[string]interface{}{
+		"terms": map[string]interface{}{
+			"Reserved": map[string]interface{}{
+				"XYZ": map[string]interface{}{
+					"termAttributes": map[string]interface{}{
+						"LeaseContractLength": "forever",
+					},
+				},
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+	}
+
+	_, err := client.GetReservedComputeCost(context.Background(), "m5.large", "US East (N. Virginia)")
+	assert.EqualError(t, err, `invalid lease contract length: "forever"`)
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestGetReservedComputeCosts_Success(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	priceItem := map[string]interface{}{
+		"product": map[string]interface{}{
+			"attributes": map[string]interface{}{
+				"instanceType": "m5.large",
+				"location":     "US East (N. Virginia)",
+				"regionCode":   "us-east-1",
+			},
+		},
+		"terms": map[string]interface{}{
+			"Reserved": map[string]interface{}{
+				"NQ3QZPMQV9": map[string]interface{}{
+					"offerTermCode": "NQ3QZPMQV9",
+					"priceDimensions": map[string]interface{}{
+						"2TG2D8R56U": map[string]interface{}{
+							"unit": "Quantity",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "525",
+							},
+						},
+						"6YS6EN2CT7": map[string]interface{}{
+							"unit": "Hrs",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "0.0300000000",
+							},
+						},
+					},
+					"termAttributes": map[string]interface{}{
+						"LeaseContractLength": "1yr",
+						"OfferingClass":       "convertible",
+						"PurchaseOption":      "Partial Upfront",
+					},
+				},
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.MatchedBy(func(input *pricing.GetProductsInput) bool {
+		return *input.Filters[0].Field == "regionCode" && *input.Filters[0].Value == "us-east-1"
+	}), mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+		Regions:       []string{"us-east-1"},
+	}
+
+	reservedCosts, err := client.GetReservedComputeCosts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*reservedCosts))
+
+	cost := (*reservedCosts)[0]
+	assert.Equal(t, attendant.ProviderNameAws, cost.Provider)
+	assert.Equal(t, "m5.large", cost.Identifier)
+	assert.Equal(t, "us-east-1", cost.Location)
+	assert.Equal(t, 1, cost.TermYears)
+	assert.Equal(t, "convertible", cost.OfferingClass)
+	assert.Equal(t, "Partial Upfront", cost.PurchaseOption)
+	assert.Equal(t, "USD", cost.Currency)
+	assert.Equal(t, 525.0, cost.UpfrontFee)
+	assert.Equal(t, 0.03, cost.HourlyRate)
+	assert.InDelta(t, 525.0/attendant.HoursPerYear+0.03, cost.EffectiveHourlyRate, 0.000001)
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestGetComputeCost_PriceDimensions(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	priceItem := map[string]interface{}{
+		"terms": map[string]interface{}{
+			"OnDemand": map[string]interface{}{
+				"XYZ": map[string]interface{}{
+					"priceDimensions": map[string]interface{}{
+						"ABC": map[string]interface{}{
+							"rateCode":    "ABC",
+							"description": "$0.0116 per On Demand Linux t2.micro Instance Hour",
+							"beginRange":  "0",
+							"endRange":    "Inf",
+							"unit":        "Hrs",
+							"pricePerUnit": map[string]interface{}{
+								"USD": "0.0116000000",
+								"CNY": "0.0840000000",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	priceItemJSON, _ := json.Marshal(priceItem)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pricing.GetProductsOutput{
+			PriceList: []string{string(priceItemJSON)},
+		}, nil)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+	}
+
+	computeCosts, err := client.GetComputeCost(context.Background(), "t2.micro", "US East (N. Virginia)")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*computeCosts))
+
+	assert.Equal(t, "USD", *(*computeCosts)[0].Currency)
+	assert.Equal(t, attendant.CostUnitHours, *(*computeCosts)[0].Unit)
+	assert.Equal(t, 0.0116, *(*computeCosts)[0].PricePerUnit)
+
+	assert.Equal(t, "CNY", *(*computeCosts)[1].Currency)
+	assert.Equal(t, attendant.CostUnitHours, *(*computeCosts)[1].Unit)
+	assert.Equal(t, 0.084, *(*computeCosts)[1].PricePerUnit)
+
+	mockPricingClient.AssertExpectations(t)
+}
+
+func TestPriceDimensionParse(t *testing.T) {
+	priceDimension := wrapper.AwsPriceDimension{
+		RateCode:     "ABC",
+		Description:  "Upfront Fee",
+		BeginRange:   "0",
+		EndRange:     "Inf",
+		Unit:         "Quantity",
+		PricePerUnit: map[string]string{"usd": "1051"},
+	}
+
+	price, err := priceDimension.Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "Upfront Fee", price.Description)
+	assert.Equal(t, attendant.CostUnitQuantity, price.Unit)
+	assert.Equal(t, 0.0, price.BeginRange)
+	assert.True(t, math.IsInf(price.EndRange, 1))
+	assert.Equal(t, []string{"USD"}, price.GetCurrencies())
+	assert.Equal(t, 1051.0, price.PricePerUnit["USD"])
+
+	priceDimension.PricePerUnit["EUR"] = "not-a-number"
+
+	_, err = priceDimension.Parse()
+	assert.Error(t, err)
+}
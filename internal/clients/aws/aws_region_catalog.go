@@ -0,0 +1,118 @@
+package aws
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	LabelTopologyRegion = "topology.kubernetes.io/region"
+	LabelTopologyZone   = "topology.kubernetes.io/zone"
+
+	RegionTypeRegion         = "AWS Region"
+	RegionTypeLocalZone      = "AWS Local Zone"
+	RegionTypeWavelengthZone = "AWS Wavelength Zone"
+)
+
+//go:embed data/regions.json
+var embeddedRegionCatalog []byte
+
+type AwsRegion struct {
+	Code         string `json:"code"`
+	Location     string `json:"location"`
+	Type         string `json:"type"`
+	ParentRegion string `json:"parentRegion,omitempty"`
+}
+
+type AwsRegionCatalog struct {
+	Version string      `json:"version"`
+	Regions []AwsRegion `json:"regions"`
+
+	byCode     map[string]AwsRegion
+	byLocation map[string]AwsRegion
+}
+
+func NewAwsRegionCatalog() (*AwsRegionCatalog, error) {
+	return LoadAwsRegionCatalog(embeddedRegionCatalog)
+}
+
+func LoadAwsRegionCatalog(data []byte) (*AwsRegionCatalog, error) {
+	var catalog AwsRegionCatalog
+
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to decode region catalog: %v", err)
+	}
+
+	if catalog.Version == "" {
+		return nil, fmt.Errorf("region catalog has no version")
+	}
+
+	catalog.byCode = make(map[string]AwsRegion)
+	catalog.byLocation = make(map[string]AwsRegion)
+
+	for _, region := range catalog.Regions {
+		if region.Code == "" || region.Location == "" {
+			return nil, fmt.Errorf("region catalog entry is missing a code or location: %+v", region)
+		}
+
+		catalog.byCode[strings.ToLower(region.Code)] = region
+		catalog.byLocation[strings.ToLower(region.Location)] = region
+	}
+
+	return &catalog, nil
+}
+
+// GetRegion resolves a region, local zone or wavelength zone code. Availability zone names such as
+// us-west-2-lax-1a are resolved to their zone group (us-west-2-lax-1) when no exact entry exists.
+func (c *AwsRegionCatalog) GetRegion(code string) (*AwsRegion, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	if region, ok := c.byCode[code]; ok {
+		return &region, nil
+	}
+
+	if trimmed := strings.TrimRight(code, "abcdefghijklmnopqrstuvwxyz"); trimmed != code {
+		if region, ok := c.byCode[trimmed]; ok {
+			return &region, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown region code: %s", code)
+}
+
+func (c *AwsRegionCatalog) GetPricingLocation(code string) (string, error) {
+	region, err := c.GetRegion(code)
+	if err != nil {
+		return "", err
+	}
+
+	return region.Location, nil
+}
+
+func (c *AwsRegionCatalog) GetRegionCode(location string) (string, error) {
+	region, ok := c.byLocation[strings.ToLower(strings.TrimSpace(location))]
+	if !ok {
+		return "", fmt.Errorf("unknown pricing location: %s", location)
+	}
+
+	return region.Code, nil
+}
+
+// GetPricingLocationForLabels prefers the node's zone label when it names a local or wavelength zone,
+// since those are priced separately from their parent region, and falls back to the region label.
+func (c *AwsRegionCatalog) GetPricingLocationForLabels(labels map[string]string) (string, error) {
+	if zone, ok := labels[LabelTopologyZone]; ok {
+		if region, err := c.GetRegion(zone); err == nil && region.Type != RegionTypeRegion {
+			return region.Location, nil
+		}
+	}
+
+	regionCode, ok := labels[LabelTopologyRegion]
+	if !ok {
+		return "", fmt.Errorf("missing label: %s", LabelTopologyRegion)
+	}
+
+	return c.GetPricingLocation(regionCode)
+}
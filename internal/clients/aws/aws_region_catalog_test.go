@@ -0,0 +1,95 @@
+package aws_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/aws"
+	mocks "github.com/be-heroes/ultron-attendant/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegionCatalogTranslation(t *testing.T) {
+	catalog, err := wrapper.NewAwsRegionCatalog()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, catalog.Version)
+
+	location, err := catalog.GetPricingLocation("us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "US East (N. Virginia)", location)
+
+	location, err = catalog.GetPricingLocation("us-west-2-lax-1a")
+	assert.NoError(t, err)
+	assert.Equal(t, "US West (Los Angeles)", location)
+
+	location, err = catalog.GetPricingLocation("us-east-1-wl1-bos-wlz-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "US East (Verizon) - Boston", location)
+
+	regionCode, err := catalog.GetRegionCode("EU (Frankfurt)")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-central-1", regionCode)
+
+	_, err = catalog.GetPricingLocation("mars-north-1")
+	assert.EqualError(t, err, "unknown region code: mars-north-1")
+
+	_, err = catalog.GetRegionCode("Mars (Olympus Mons)")
+	assert.EqualError(t, err, "unknown pricing location: Mars (Olympus Mons)")
+}
+
+func TestRegionCatalogLabels(t *testing.T) {
+	catalog, err := wrapper.NewAwsRegionCatalog()
+	assert.NoError(t, err)
+
+	location, err := catalog.GetPricingLocationForLabels(map[string]string{
+		wrapper.LabelTopologyRegion: "us-west-2",
+		wrapper.LabelTopologyZone:   "us-west-2b",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "US West (Oregon)", location)
+
+	location, err = catalog.GetPricingLocationForLabels(map[string]string{
+		wrapper.LabelTopologyRegion: "us-west-2",
+		wrapper.LabelTopologyZone:   "us-west-2-lax-1a",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "US West (Los Angeles)", location)
+
+	_, err = catalog.GetPricingLocationForLabels(map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestLoadRegionCatalogInvalid(t *testing.T) {
+	_, err := wrapper.LoadAwsRegionCatalog([]byte(`{ invalid json `))
+	assert.Error(t, err)
+
+	_, err = wrapper.LoadAwsRegionCatalog([]byte(`{"regions": []}`))
+	assert.EqualError(t, err, "region catalog has no version")
+
+	_, err = wrapper.LoadAwsRegionCatalog([]byte(`{"version": "1", "regions": [{"code": "us-east-1"}]}`))
+	assert.Error(t, err)
+}
+
+func TestGetComputeCostByRegionCode(t *testing.T) {
+	mockPricingClient := new(mocks.IPricingAPI)
+
+	mockPricingClient.On("GetProducts", mock.Anything, mock.MatchedBy(func(input *pricing.GetProductsInput) bool {
+		return *input.Filters[1].Field == "location" && *input.Filters[1].Value == "EU (Stockholm)"
+	}), mock.Anything).
+		Return(&pricing.GetProductsOutput{}, nil)
+
+	catalog, err := wrapper.NewAwsRegionCatalog()
+	assert.NoError(t, err)
+
+	client := &wrapper.AwsClient{
+		PricingClient: mockPricingClient,
+		RegionCatalog: catalog,
+	}
+
+	_, err = client.GetComputeCostByRegionCode(context.Background(), "m5.large", "eu-north-1")
+	assert.NoError(t, err)
+
+	mockPricingClient.AssertExpectations(t)
+}
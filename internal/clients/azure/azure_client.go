@@ -4,12 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
 	ultron "github.com/be-heroes/ultron/pkg"
 )
 
+const DefaultBaseUrl = "https://prices.azure.com/api/retail/prices"
+
+type AzurePriceClass string
+
+const (
+	PriceClassDurable     AzurePriceClass = "durable"
+	PriceClassSpot        AzurePriceClass = "spot"
+	PriceClassLowPriority AzurePriceClass = "low-priority"
+	PriceClassReservation AzurePriceClass = "reservation"
+	PriceClassDevTest     AzurePriceClass = "devtest"
+)
+
 type AzureComputePricesResponse struct {
 	Items        []AzureComputePrice `json:"Items"`
 	Count        int                 `json:"Count"`
@@ -37,16 +53,43 @@ type AzureComputePrice struct {
 	Type                 string  `json:"type"`
 	IsPrimaryMeterRegion bool    `json:"isPrimaryMeterRegion"`
 	ArmSkuName           string  `json:"armSkuName"`
+	ReservationTerm      string  `json:"reservationTerm,omitempty"`
+}
+
+// AzureReservedCost is a reservation price with its term price amortized over every hour of the term, so that
+// EffectiveHourlyRate can be compared directly with pay-as-you-go and spot hourly rates.
+type AzureReservedCost struct {
+	ArmSkuName          string
+	Region              string
+	ReservationTerm     string
+	TermYears           int
+	Currency            string
+	TermPrice           float64
+	EffectiveHourlyRate float64
 }
 
 type IAzureClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
+	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetComputeConfigurations(ctx context.Context, region string) (*[]ultron.ComputeConfiguration, error)
+	GetSpotComputeConfigurations(ctx context.Context, region string) (*[]ultron.ComputeConfiguration, error)
+	GetReservedComputeCost(ctx context.Context, region string) (*[]AzureReservedCost, error)
+	GetReservedComputeCosts(ctx context.Context) (*[]attendant.ReservedComputeCost, error)
 	GetComputeCost(ctx context.Context, filter string) (*[]ultron.ComputeCost, error)
+	GetComputeCostWithQuery(ctx context.Context, query *AzurePriceQuery) (*[]ultron.ComputeCost, error)
+	GetPrices(ctx context.Context, query *AzurePriceQuery) (*[]AzureComputePrice, error)
 }
 
-// TODO: Refactor client to return compute configs, as well as compute costs and adhere to the same interface as the emma & wisp clients
 type AzureClient struct {
-	httpClient *http.Client
-	baseUrl    string
+	httpClient    *http.Client
+	baseUrl       string
+	VmSizeCatalog *AzureVmSizeCatalog
+	Regions       []string
+	CurrencyCode  string
+	ApiVersion    string
 }
 
 func NewAzureClient(httpClient *http.Client, baseUrl string) *AzureClient {
@@ -54,14 +97,241 @@ func NewAzureClient(httpClient *http.Client, baseUrl string) *AzureClient {
 		httpClient = &http.Client{}
 	}
 
+	// The embedded catalog is validated by TestNewAzureVmSizeCatalog, so failing to load it is a build defect
+	// rather than a runtime condition.
+	vmSizeCatalog, err := NewAzureVmSizeCatalog()
+	if err != nil {
+		panic(err)
+	}
+
 	return &AzureClient{
-		httpClient: httpClient,
-		baseUrl:    baseUrl,
+		httpClient:    httpClient,
+		baseUrl:       baseUrl,
+		VmSizeCatalog: vmSizeCatalog,
+	}
+}
+
+func (c *AzureClient) GetName() string {
+	return attendant.ProviderNameAzure
+}
+
+func (c *AzureClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+}
+
+func (c *AzureClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	durableConfigs, err := c.GetDurableComputeConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralConfigs, err := c.GetEphemeralComputeConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append(*durableConfigs, *ephemeralConfigs...)
+
+	return &result, nil
+}
+
+func (c *AzureClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	result := []ultron.ComputeConfiguration{}
+
+	for _, region := range c.Regions {
+		configs, err := c.GetComputeConfigurations(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *configs...)
+	}
+
+	return &result, nil
+}
+
+func (c *AzureClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	result := []ultron.ComputeConfiguration{}
+
+	for _, region := range c.Regions {
+		configs, err := c.GetSpotComputeConfigurations(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *configs...)
 	}
+
+	return &result, nil
+}
+
+func (c *AzureClient) GetComputeConfigurations(ctx context.Context, region string) (*[]ultron.ComputeConfiguration, error) {
+	return c.getComputeConfigurations(ctx, region, PriceClassDurable, ultron.ComputeTypeDurable)
+}
+
+func (c *AzureClient) GetSpotComputeConfigurations(ctx context.Context, region string) (*[]ultron.ComputeConfiguration, error) {
+	return c.getComputeConfigurations(ctx, region, PriceClassSpot, ultron.ComputeTypeEphemeral)
+}
+
+func (c *AzureClient) getComputeConfigurations(ctx context.Context, region string, priceClass AzurePriceClass, computeType ultron.ComputeType) (*[]ultron.ComputeConfiguration, error) {
+	if c.VmSizeCatalog == nil {
+		return nil, fmt.Errorf("vm size catalog is not configured")
+	}
+
+	query := NewAzurePriceQuery().
+		WithServiceName(ServiceNameVirtualMachines).
+		WithRegion(region).
+		WithPriceType(PriceTypeConsumption).
+		WithCurrencyCode(c.CurrencyCode).
+		WithApiVersion(c.ApiVersion)
+
+	items, err := c.GetPrices(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ultron.ComputeConfiguration{}
+
+	for _, item := range *items {
+		if item.IsWindows() || item.Classify() != priceClass {
+			continue
+		}
+
+		vmSize, ok := c.VmSizeCatalog.GetVmSize(item.ArmSkuName)
+		if !ok {
+			continue
+		}
+
+		results = append(results, c.mapConfiguration(&item, vmSize, computeType))
+	}
+
+	return &results, nil
+}
+
+func (c *AzureClient) GetReservedComputeCost(ctx context.Context, region string) (*[]AzureReservedCost, error) {
+	query := NewAzurePriceQuery().
+		WithServiceName(ServiceNameVirtualMachines).
+		WithRegion(region).
+		WithPriceType(PriceTypeReservation).
+		WithCurrencyCode(c.CurrencyCode).
+		WithApiVersion(c.ApiVersion)
+
+	items, err := c.GetPrices(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []AzureReservedCost{}
+
+	for _, item := range *items {
+		if item.Classify() != PriceClassReservation {
+			continue
+		}
+
+		termYears, err := parseReservationTerm(item.ReservationTerm)
+		if err != nil {
+			return nil, err
+		}
+
+		hourlyPrice, err := item.GetHourlyPrice()
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, AzureReservedCost{
+			ArmSkuName:          item.ArmSkuName,
+			Region:              item.ArmRegionName,
+			ReservationTerm:     item.ReservationTerm,
+			TermYears:           termYears,
+			Currency:            item.CurrencyCode,
+			TermPrice:           item.UnitPrice,
+			EffectiveHourlyRate: hourlyPrice,
+		})
+	}
+
+	return &results, nil
+}
+
+// GetReservedComputeCosts returns the amortized reservation prices of every VM size in Regions. Reservations
+// are paid for the whole term upfront, so the term price is the upfront fee and there is no recurring rate.
+func (c *AzureClient) GetReservedComputeCosts(ctx context.Context) (*[]attendant.ReservedComputeCost, error) {
+	results := []attendant.ReservedComputeCost{}
+
+	for _, region := range c.Regions {
+		reservedCosts, err := c.GetReservedComputeCost(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reservedCost := range *reservedCosts {
+			results = append(results, attendant.ReservedComputeCost{
+				Provider:            attendant.ProviderNameAzure,
+				Identifier:          reservedCost.ArmSkuName,
+				Location:            reservedCost.Region,
+				TermYears:           reservedCost.TermYears,
+				Currency:            reservedCost.Currency,
+				UpfrontFee:          reservedCost.TermPrice,
+				EffectiveHourlyRate: reservedCost.EffectiveHourlyRate,
+			})
+		}
+	}
+
+	return &results, nil
 }
 
 func (c *AzureClient) GetComputeCost(ctx context.Context, filter string) (*[]ultron.ComputeCost, error) {
-	var allItems []ultron.ComputeCost
+	values := url.Values{}
+	if filter != "" {
+		values.Set("$filter", filter)
+	}
+
+	items, err := c.getPrices(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapComputeCosts(items)
+}
+
+func (c *AzureClient) GetComputeCostWithQuery(ctx context.Context, query *AzurePriceQuery) (*[]ultron.ComputeCost, error) {
+	items, err := c.GetPrices(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapComputeCosts(*items)
+}
+
+func (c *AzureClient) GetPrices(ctx context.Context, query *AzurePriceQuery) (*[]AzureComputePrice, error) {
+	items, err := c.getPrices(ctx, query.Values())
+	if err != nil {
+		return nil, err
+	}
+
+	return &items, nil
+}
+
+func mapComputeCosts(items []AzureComputePrice) (*[]ultron.ComputeCost, error) {
+	allItems := []ultron.ComputeCost{}
+
+	for _, item := range items {
+		hourlyPrice, err := item.GetHourlyPrice()
+		if err != nil {
+			return nil, err
+		}
+
+		allItems = append(allItems, ultron.ComputeCost{
+			Currency:     &item.CurrencyCode,
+			Unit:         &item.UnitOfMeasure,
+			PricePerUnit: &hourlyPrice,
+		})
+	}
+
+	return &allItems, nil
+}
+
+func (c *AzureClient) getPrices(ctx context.Context, values url.Values) ([]AzureComputePrice, error) {
+	var allItems []AzureComputePrice
 
 	u, err := url.Parse(c.baseUrl)
 	if err != nil {
@@ -69,8 +339,8 @@ func (c *AzureClient) GetComputeCost(ctx context.Context, filter string) (*[]ult
 	}
 
 	q := u.Query()
-	if filter != "" {
-		q.Set("$filter", filter)
+	for key := range values {
+		q.Set(key, values.Get(key))
 	}
 	u.RawQuery = q.Encode()
 
@@ -96,13 +366,7 @@ func (c *AzureClient) GetComputeCost(ctx context.Context, filter string) (*[]ult
 			return nil, fmt.Errorf("failed to decode JSON response: %v", err)
 		}
 
-		for _, item := range pricesResponse.Items {
-			allItems = append(allItems, ultron.ComputeCost{
-				Currency:     &item.CurrencyCode,
-				Unit:         &item.UnitOfMeasure,
-				PricePerUnit: func(f float64) *float64 { v := float64(f); return &v }(item.UnitPrice),
-			})
-		}
+		allItems = append(allItems, pricesResponse.Items...)
 
 		if pricesResponse.NextPageLink == "" {
 			break
@@ -114,5 +378,97 @@ func (c *AzureClient) GetComputeCost(ctx context.Context, filter string) (*[]ult
 		}
 	}
 
-	return &allItems, nil
+	return allItems, nil
+}
+
+func (c *AzureClient) mapConfiguration(item *AzureComputePrice, vmSize *AzureVmSize, computeType ultron.ComputeType) ultron.ComputeConfiguration {
+	provider := attendant.ProviderNameAzure
+	osType := "Linux"
+	ramGb := int64(math.Round(vmSize.MemoryGb))
+	priceUnit := attendant.NormalizeCostUnit(item.UnitOfMeasure)
+	priceCurrency := item.CurrencyCode
+	price := item.UnitPrice
+
+	config := ultron.ComputeConfiguration{
+		Identifier:  &vmSize.Name,
+		Provider:    &provider,
+		Location:    &item.ArmRegionName,
+		DataCenter:  &item.Location,
+		OsType:      &osType,
+		VCpuType:    &vmSize.Architecture,
+		VCpu:        &vmSize.VCpu,
+		RamGb:       &ramGb,
+		ComputeType: computeType,
+		Cost: &ultron.ComputeCost{
+			Unit:         &priceUnit,
+			Currency:     &priceCurrency,
+			PricePerUnit: &price,
+		},
+	}
+
+	if vmSize.TempDiskGb > 0 {
+		volumeType := "TempDisk"
+
+		config.VolumeGb = &vmSize.TempDiskGb
+		config.VolumeType = &volumeType
+	}
+
+	return config
+}
+
+// Classify tells pay-as-you-go, Spot, Low Priority, reservation and DevTest prices apart. Spot and Low Priority
+// meters share the ArmSkuName of the pay-as-you-go meter and are only distinguished by their name suffix.
+func (p *AzureComputePrice) Classify() AzurePriceClass {
+	switch AzurePriceType(p.Type) {
+	case PriceTypeReservation:
+		return PriceClassReservation
+	case PriceTypeDevTestConsumption:
+		return PriceClassDevTest
+	}
+
+	for _, name := range []string{p.SkuName, p.MeterName} {
+		if strings.HasSuffix(name, " Spot") {
+			return PriceClassSpot
+		}
+
+		if strings.HasSuffix(name, " Low Priority") {
+			return PriceClassLowPriority
+		}
+	}
+
+	return PriceClassDurable
+}
+
+// GetHourlyPrice returns the unit price, except for reservations where the unit price covers the whole
+// reservation term and is amortized over every hour of it.
+func (p *AzureComputePrice) GetHourlyPrice() (float64, error) {
+	if p.Classify() != PriceClassReservation {
+		return p.UnitPrice, nil
+	}
+
+	termYears, err := parseReservationTerm(p.ReservationTerm)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.UnitPrice / float64(termYears*attendant.HoursPerYear), nil
+}
+
+func (p *AzureComputePrice) IsWindows() bool {
+	return strings.Contains(p.ProductName, "Windows")
+}
+
+// parseReservationTerm parses reservation terms such as "1 Year" or "3 Years".
+func parseReservationTerm(value string) (int, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 || !strings.HasPrefix(strings.ToLower(fields[1]), "year") {
+		return 0, fmt.Errorf("invalid reservation term: %q", value)
+	}
+
+	years, err := strconv.Atoi(fields[0])
+	if err != nil || years <= 0 {
+		return 0, fmt.Errorf("invalid reservation term: %q", value)
+	}
+
+	return years, nil
 }
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/azure"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
 	ultron "github.com/be-heroes/ultron/pkg"
 	"github.com/stretchr/testify/assert"
 )
@@ -64,7 +66,7 @@ func TestGetComputeCost(t *testing.T) {
 		assert.Equal(t, expectedItem.UnitOfMeasure, *cost.Unit, "Unit does not match")
 
 		assert.NotNil(t, cost.PricePerUnit, "Expected PricePerUnit not to be nil")
-		assert.Equal(t, float32(expectedItem.UnitPrice), *cost.PricePerUnit, "PricePerUnit does not match")
+		assert.Equal(t, expectedItem.UnitPrice, *cost.PricePerUnit, "PricePerUnit does not match")
 	}
 }
 
@@ -223,3 +225,299 @@ func TestGetComputeCostInvalidJSON(t *testing.T) {
 	assert.Error(t, err, "Expected an error from GetComputeCost")
 	assert.Contains(t, err.Error(), "failed to decode JSON response", "Error message does not contain expected text")
 }
+
+func TestGetComputeConfigurations(t *testing.T) {
+	sampleResponse := wrapper.AzureComputePricesResponse{
+		Items: []wrapper.AzureComputePrice{
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.096,
+				ArmRegionName: "eastus",
+				Location:      "US East",
+				ProductName:   "Virtual Machines Dv3 Series",
+				SkuName:       "D2 v3",
+				MeterName:     "D2 v3",
+				ServiceName:   "Virtual Machines",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.188,
+				ArmRegionName: "eastus",
+				Location:      "US East",
+				ProductName:   "Virtual Machines Dv3 Series Windows",
+				SkuName:       "D2 v3",
+				ServiceName:   "Virtual Machines",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.0144,
+				ArmRegionName: "eastus",
+				Location:      "US East",
+				ProductName:   "Virtual Machines Dv3 Series",
+				SkuName:       "D2 v3 Spot",
+				ServiceName:   "Virtual Machines",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     1.0,
+				ArmRegionName: "eastus",
+				ProductName:   "Virtual Machines Unknown Series",
+				SkuName:       "Unknown",
+				ServiceName:   "Virtual Machines",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_Unknown",
+			},
+		},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "serviceName eq 'Virtual Machines' and armRegionName eq 'eastus' and priceType eq 'Consumption'", r.URL.Query().Get("$filter"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sampleResponse)
+	}))
+	defer testServer.Close()
+
+	client := wrapper.NewAzureClient(&http.Client{}, testServer.URL)
+	client.Regions = []string{"eastus"}
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetDurableComputeConfigurations")
+	assert.Equal(t, 1, len(*configs), "Expected only the Linux pay-as-you-go meter with a known size")
+
+	config := (*configs)[0]
+	assert.Equal(t, "Standard_D2_v3", *config.Identifier)
+	assert.Equal(t, "azure", *config.Provider)
+	assert.Equal(t, "eastus", *config.Location)
+	assert.Equal(t, "US East", *config.DataCenter)
+	assert.Equal(t, int64(2), *config.VCpu)
+	assert.Equal(t, int64(8), *config.RamGb)
+	assert.Equal(t, int64(50), *config.VolumeGb)
+	assert.Equal(t, "x64", *config.VCpuType)
+	assert.Equal(t, ultron.ComputeTypeDurable, config.ComputeType)
+	assert.Equal(t, attendant.CostUnitHours, *config.Cost.Unit)
+	assert.Equal(t, "USD", *config.Cost.Currency)
+	assert.Equal(t, 0.096, *config.Cost.PricePerUnit)
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		item     wrapper.AzureComputePrice
+		expected wrapper.AzurePriceClass
+	}{
+		{wrapper.AzureComputePrice{Type: "Consumption", SkuName: "D2 v3", MeterName: "D2 v3"}, wrapper.PriceClassDurable},
+		{wrapper.AzureComputePrice{Type: "Consumption", SkuName: "D2 v3 Spot", MeterName: "D2 v3 Spot"}, wrapper.PriceClassSpot},
+		{wrapper.AzureComputePrice{Type: "Consumption", SkuName: "D2 v3", MeterName: "D2 v3 Low Priority"}, wrapper.PriceClassLowPriority},
+		{wrapper.AzureComputePrice{Type: "Reservation", SkuName: "D2 v3", MeterName: "D2 v3"}, wrapper.PriceClassReservation},
+		{wrapper.AzureComputePrice{Type: "DevTestConsumption", SkuName: "D2 v3", MeterName: "D2 v3"}, wrapper.PriceClassDevTest},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, c.item.Classify(), "Unexpected class for %s/%s", c.item.Type, c.item.MeterName)
+	}
+}
+
+func TestGetSpotComputeConfigurations(t *testing.T) {
+	sampleResponse := wrapper.AzureComputePricesResponse{
+		Items: []wrapper.AzureComputePrice{
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.096,
+				ArmRegionName: "eastus",
+				ProductName:   "Virtual Machines Dv3 Series",
+				SkuName:       "D2 v3",
+				MeterName:     "D2 v3",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.0144,
+				ArmRegionName: "eastus",
+				ProductName:   "Virtual Machines Dv3 Series",
+				SkuName:       "D2 v3 Spot",
+				MeterName:     "D2 v3 Spot",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+			{
+				CurrencyCode:  "USD",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.0192,
+				ArmRegionName: "eastus",
+				ProductName:   "Virtual Machines Dv3 Series",
+				SkuName:       "D2 v3 Low Priority",
+				MeterName:     "D2 v3 Low Priority",
+				Type:          "Consumption",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+		},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sampleResponse)
+	}))
+	defer testServer.Close()
+
+	client := wrapper.NewAzureClient(&http.Client{}, testServer.URL)
+	client.Regions = []string{"eastus"}
+
+	configs, err := client.GetEphemeralComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetEphemeralComputeConfigurations")
+	assert.Equal(t, 1, len(*configs), "Expected only the spot meter")
+	assert.Equal(t, ultron.ComputeTypeEphemeral, (*configs)[0].ComputeType)
+	assert.Equal(t, 0.0144, *(*configs)[0].Cost.PricePerUnit)
+
+	configs, err = client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetDurableComputeConfigurations")
+	assert.Equal(t, 1, len(*configs), "Expected only the pay-as-you-go meter")
+	assert.Equal(t, ultron.ComputeTypeDurable, (*configs)[0].ComputeType)
+	assert.Equal(t, 0.096, *(*configs)[0].Cost.PricePerUnit)
+}
+
+func TestGetReservedComputeCost(t *testing.T) {
+	sampleResponse := wrapper.AzureComputePricesResponse{
+		Items: []wrapper.AzureComputePrice{
+			{
+				CurrencyCode:    "USD",
+				UnitOfMeasure:   "1 Hour",
+				UnitPrice:       525.6,
+				ArmRegionName:   "eastus",
+				SkuName:         "D2 v3",
+				MeterName:       "D2 v3",
+				Type:            "Reservation",
+				ReservationTerm: "1 Year",
+				ArmSkuName:      "Standard_D2_v3",
+			},
+			{
+				CurrencyCode:    "USD",
+				UnitOfMeasure:   "1 Hour",
+				UnitPrice:       1051.2,
+				ArmRegionName:   "eastus",
+				SkuName:         "D2 v3",
+				MeterName:       "D2 v3",
+				Type:            "Reservation",
+				ReservationTerm: "3 Years",
+				ArmSkuName:      "Standard_D2_v3",
+			},
+		},
+	}
+
+	var filter string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("$filter")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sampleResponse)
+	}))
+	defer testServer.Close()
+
+	client := wrapper.NewAzureClient(&http.Client{}, testServer.URL)
+
+	reservedCosts, err := client.GetReservedComputeCost(context.Background(), "eastus")
+	assert.NoError(t, err, "Expected no error from GetReservedComputeCost")
+	assert.Equal(t, "serviceName eq 'Virtual Machines' and armRegionName eq 'eastus' and priceType eq 'Reservation'", filter)
+	assert.Equal(t, 2, len(*reservedCosts))
+
+	assert.Equal(t, 1, (*reservedCosts)[0].TermYears)
+	assert.Equal(t, 525.6, (*reservedCosts)[0].TermPrice)
+	assert.InDelta(t, 0.06, (*reservedCosts)[0].EffectiveHourlyRate, 0.000001)
+
+	assert.Equal(t, 3, (*reservedCosts)[1].TermYears)
+	assert.Equal(t, 1051.2, (*reservedCosts)[1].TermPrice)
+	assert.InDelta(t, 0.04, (*reservedCosts)[1].EffectiveHourlyRate, 0.000001)
+
+	computeCosts, err := client.GetComputeCost(context.Background(), "priceType eq 'Reservation'")
+	assert.NoError(t, err, "Expected no error from GetComputeCost")
+	assert.InDelta(t, 0.06, *(*computeCosts)[0].PricePerUnit, 0.000001, "Expected reservation prices to be amortized per hour")
+}
+
+func TestGetReservedComputeCostInvalidTerm(t *testing.T) {
+	sampleResponse := wrapper.AzureComputePricesResponse{
+		Items: []wrapper.AzureComputePrice{
+			{
+				UnitPrice:       525.6,
+				Type:            "Reservation",
+				ReservationTerm: "Forever",
+				ArmSkuName:      "Standard_D2_v3",
+			},
+		},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sampleResponse)
+	}))
+	defer testServer.Close()
+
+	client := wrapper.NewAzureClient(&http.Client{}, testServer.URL)
+
+	_, err := client.GetReservedComputeCost(context.Background(), "eastus")
+	assert.EqualError(t, err, `invalid reservation term: "Forever"`)
+}
+
+func TestGetReservedComputeCosts(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		region := "westeurope"
+		if strings.Contains(r.URL.Query().Get("$filter"), "'eastus'") {
+			region = "eastus"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(wrapper.AzureComputePricesResponse{
+			Items: []wrapper.AzureComputePrice{
+				{
+					CurrencyCode:    "USD",
+					UnitOfMeasure:   "1 Hour",
+					UnitPrice:       525.6,
+					ArmRegionName:   region,
+					SkuName:         "D2 v3",
+					MeterName:       "D2 v3",
+					Type:            "Reservation",
+					ReservationTerm: "1 Year",
+					ArmSkuName:      "Standard_D2_v3",
+				},
+			},
+		})
+	}))
+	defer testServer.Close()
+
+	client := wrapper.NewAzureClient(&http.Client{}, testServer.URL)
+	client.Regions = []string{"eastus", "westeurope"}
+
+	var provider attendant.IProvider = client
+
+	costProvider, ok := provider.(attendant.IReservedCostProvider)
+	assert.True(t, ok, "Expected the Azure client to price reserved capacity")
+
+	reservedCosts, err := costProvider.GetReservedComputeCosts(context.Background())
+	assert.NoError(t, err, "Expected no error from GetReservedComputeCosts")
+	assert.Equal(t, 2, len(*reservedCosts), "Expected one reservation per region")
+
+	for i, region := range client.Regions {
+		reservedCost := (*reservedCosts)[i]
+
+		assert.Equal(t, attendant.ProviderNameAzure, reservedCost.Provider)
+		assert.Equal(t, "Standard_D2_v3", reservedCost.Identifier)
+		assert.Equal(t, region, reservedCost.Location)
+		assert.Equal(t, 1, reservedCost.TermYears)
+		assert.Equal(t, "USD", reservedCost.Currency)
+		assert.Equal(t, 525.6, reservedCost.UpfrontFee)
+		assert.Equal(t, 0.0, reservedCost.HourlyRate)
+		assert.InDelta(t, 0.06, reservedCost.EffectiveHourlyRate, 0.000001)
+	}
+}
@@ -0,0 +1,156 @@
+package azure
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type AzurePriceType string
+
+const (
+	ApiVersionDefault = ""
+	ApiVersionPreview = "2023-01-01-preview"
+
+	PriceTypeConsumption        AzurePriceType = "Consumption"
+	PriceTypeReservation        AzurePriceType = "Reservation"
+	PriceTypeDevTestConsumption AzurePriceType = "DevTestConsumption"
+
+	ServiceNameVirtualMachines = "Virtual Machines"
+)
+
+// AzurePriceQuery renders the $filter, currencyCode and api-version parameters of a retail prices API request.
+// Values within a field are OR'ed together and fields are AND'ed, so the rendered filter never depends on
+// OData operator precedence.
+type AzurePriceQuery struct {
+	ServiceNames       []string
+	ServiceFamilies    []string
+	Regions            []string
+	ArmSkuNames        []string
+	PriceTypes         []AzurePriceType
+	EffectiveStartDate *time.Time
+	CurrencyCode       string
+	ApiVersion         string
+}
+
+func NewAzurePriceQuery() *AzurePriceQuery {
+	return &AzurePriceQuery{}
+}
+
+func (q *AzurePriceQuery) WithServiceName(serviceNames ...string) *AzurePriceQuery {
+	q.ServiceNames = append(q.ServiceNames, serviceNames...)
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithServiceFamily(serviceFamilies ...string) *AzurePriceQuery {
+	q.ServiceFamilies = append(q.ServiceFamilies, serviceFamilies...)
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithRegion(regions ...string) *AzurePriceQuery {
+	q.Regions = append(q.Regions, regions...)
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithArmSkuName(armSkuNames ...string) *AzurePriceQuery {
+	q.ArmSkuNames = append(q.ArmSkuNames, armSkuNames...)
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithPriceType(priceTypes ...AzurePriceType) *AzurePriceQuery {
+	q.PriceTypes = append(q.PriceTypes, priceTypes...)
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithEffectiveStartDate(effectiveStartDate time.Time) *AzurePriceQuery {
+	q.EffectiveStartDate = &effectiveStartDate
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithCurrencyCode(currencyCode string) *AzurePriceQuery {
+	q.CurrencyCode = currencyCode
+
+	return q
+}
+
+func (q *AzurePriceQuery) WithApiVersion(apiVersion string) *AzurePriceQuery {
+	q.ApiVersion = apiVersion
+
+	return q
+}
+
+func (q *AzurePriceQuery) Filter() string {
+	priceTypes := make([]string, len(q.PriceTypes))
+
+	for i, priceType := range q.PriceTypes {
+		priceTypes[i] = string(priceType)
+	}
+
+	var clauses []string
+
+	for _, clause := range []string{
+		renderClause("serviceName", q.ServiceNames),
+		renderClause("serviceFamily", q.ServiceFamilies),
+		renderClause("armRegionName", q.Regions),
+		renderClause("armSkuName", q.ArmSkuNames),
+		renderClause("priceType", priceTypes),
+	} {
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	if q.EffectiveStartDate != nil {
+		clauses = append(clauses, fmt.Sprintf("effectiveStartDate ge %s", q.EffectiveStartDate.UTC().Format(time.RFC3339)))
+	}
+
+	return strings.Join(clauses, " and ")
+}
+
+func (q *AzurePriceQuery) Values() url.Values {
+	values := url.Values{}
+
+	if filter := q.Filter(); filter != "" {
+		values.Set("$filter", filter)
+	}
+
+	if q.CurrencyCode != "" {
+		values.Set("currencyCode", quote(strings.ToUpper(q.CurrencyCode)))
+	}
+
+	if q.ApiVersion != "" {
+		values.Set("api-version", q.ApiVersion)
+	}
+
+	return values
+}
+
+func renderClause(field string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	comparisons := make([]string, len(values))
+
+	for i, value := range values {
+		comparisons[i] = fmt.Sprintf("%s eq %s", field, quote(value))
+	}
+
+	if len(comparisons) == 1 {
+		return comparisons[0]
+	}
+
+	return "(" + strings.Join(comparisons, " or ") + ")"
+}
+
+// quote renders an OData string literal, escaping embedded single quotes by doubling them.
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,90 @@
+package azure_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/azure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAzurePriceQueryFilter(t *testing.T) {
+	query := wrapper.NewAzurePriceQuery().
+		WithServiceName(wrapper.ServiceNameVirtualMachines).
+		WithRegion("eastus", "westeurope").
+		WithArmSkuName("Standard_D2_v3").
+		WithPriceType(wrapper.PriceTypeConsumption, wrapper.PriceTypeReservation).
+		WithEffectiveStartDate(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	expectedFilter := "serviceName eq 'Virtual Machines'" +
+		" and (armRegionName eq 'eastus' or armRegionName eq 'westeurope')" +
+		" and armSkuName eq 'Standard_D2_v3'" +
+		" and (priceType eq 'Consumption' or priceType eq 'Reservation')" +
+		" and effectiveStartDate ge 2024-10-01T00:00:00Z"
+
+	assert.Equal(t, expectedFilter, query.Filter(), "Rendered filter does not match")
+}
+
+func TestAzurePriceQueryFilterQuoting(t *testing.T) {
+	query := wrapper.NewAzurePriceQuery().WithServiceName("Operator's Service")
+
+	assert.Equal(t, "serviceName eq 'Operator''s Service'", query.Filter(), "Expected embedded quotes to be escaped")
+	assert.Equal(t, "", wrapper.NewAzurePriceQuery().Filter(), "Expected an empty query to render no filter")
+}
+
+func TestAzurePriceQueryValues(t *testing.T) {
+	query := wrapper.NewAzurePriceQuery().
+		WithServiceName(wrapper.ServiceNameVirtualMachines).
+		WithCurrencyCode("eur").
+		WithApiVersion(wrapper.ApiVersionPreview)
+
+	values := query.Values()
+
+	assert.Equal(t, "serviceName eq 'Virtual Machines'", values.Get("$filter"))
+	assert.Equal(t, "'EUR'", values.Get("currencyCode"))
+	assert.Equal(t, wrapper.ApiVersionPreview, values.Get("api-version"))
+}
+
+func TestGetComputeCostWithQuery(t *testing.T) {
+	sampleResponse := wrapper.AzureComputePricesResponse{
+		Items: []wrapper.AzureComputePrice{
+			{
+				CurrencyCode:  "EUR",
+				UnitOfMeasure: "1 Hour",
+				UnitPrice:     0.089,
+				ArmRegionName: "westeurope",
+				ServiceName:   "Virtual Machines",
+				ArmSkuName:    "Standard_D2_v3",
+			},
+		},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "serviceName eq 'Virtual Machines' and armRegionName eq 'westeurope'", q.Get("$filter"))
+		assert.Equal(t, "'EUR'", q.Get("currencyCode"))
+		assert.Equal(t, wrapper.ApiVersionPreview, q.Get("api-version"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sampleResponse)
+	}))
+	defer testServer.Close()
+
+	client := wrapper.NewAzureClient(&http.Client{}, testServer.URL)
+
+	query := wrapper.NewAzurePriceQuery().
+		WithServiceName(wrapper.ServiceNameVirtualMachines).
+		WithRegion("westeurope").
+		WithCurrencyCode("EUR").
+		WithApiVersion(wrapper.ApiVersionPreview)
+
+	computeCosts, err := client.GetComputeCostWithQuery(context.Background(), query)
+	assert.NoError(t, err, "Expected no error from GetComputeCostWithQuery")
+	assert.Equal(t, 1, len(*computeCosts))
+	assert.Equal(t, "EUR", *(*computeCosts)[0].Currency)
+	assert.Equal(t, 0.089, *(*computeCosts)[0].PricePerUnit)
+}
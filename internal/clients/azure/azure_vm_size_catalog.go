@@ -0,0 +1,68 @@
+package azure
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:embed data/vm_sizes.json
+var embeddedVmSizeCatalog []byte
+
+type AzureVmSize struct {
+	Name         string  `json:"name"`
+	VCpu         int64   `json:"vCpu"`
+	MemoryGb     float64 `json:"memoryGb"`
+	TempDiskGb   int64   `json:"tempDiskGb"`
+	Architecture string  `json:"architecture"`
+	Gpus         int64   `json:"gpus,omitempty"`
+}
+
+type AzureVmSizeCatalog struct {
+	Version string        `json:"version"`
+	Sizes   []AzureVmSize `json:"sizes"`
+
+	byName map[string]AzureVmSize
+}
+
+// The embedded catalog is parsed once and shared by every client, since it is never modified after loading.
+var embeddedAzureVmSizeCatalog = sync.OnceValues(func() (*AzureVmSizeCatalog, error) {
+	return LoadAzureVmSizeCatalog(embeddedVmSizeCatalog)
+})
+
+func NewAzureVmSizeCatalog() (*AzureVmSizeCatalog, error) {
+	return embeddedAzureVmSizeCatalog()
+}
+
+func LoadAzureVmSizeCatalog(data []byte) (*AzureVmSizeCatalog, error) {
+	var catalog AzureVmSizeCatalog
+
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to decode vm size catalog: %v", err)
+	}
+
+	catalog.byName = make(map[string]AzureVmSize)
+
+	for _, size := range catalog.Sizes {
+		if size.Name == "" || size.VCpu <= 0 || size.MemoryGb <= 0 {
+			return nil, fmt.Errorf("invalid vm size catalog entry: %+v", size)
+		}
+
+		catalog.byName[strings.ToLower(size.Name)] = size
+	}
+
+	return &catalog, nil
+}
+
+// GetVmSize looks up a size by its ArmSkuName, e.g. Standard_D2_v3. Lookups are case-insensitive since the
+// retail prices API is not consistent about casing.
+func (c *AzureVmSizeCatalog) GetVmSize(armSkuName string) (*AzureVmSize, bool) {
+	size, ok := c.byName[strings.ToLower(armSkuName)]
+	if !ok {
+		return nil, false
+	}
+
+	return &size, true
+}
@@ -0,0 +1,42 @@
+package azure_test
+
+import (
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/azure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAzureVmSizeCatalog(t *testing.T) {
+	catalog, err := wrapper.NewAzureVmSizeCatalog()
+	assert.NoError(t, err, "Expected embedded catalog to load")
+	assert.NotEmpty(t, catalog.Version, "Expected catalog version to be set")
+
+	vmSize, ok := catalog.GetVmSize("standard_d2_v3")
+	assert.True(t, ok, "Expected Standard_D2_v3 to be present")
+	assert.Equal(t, int64(2), vmSize.VCpu)
+	assert.Equal(t, 8.0, vmSize.MemoryGb)
+	assert.Equal(t, int64(50), vmSize.TempDiskGb)
+	assert.Equal(t, "x64", vmSize.Architecture)
+
+	_, ok = catalog.GetVmSize("Standard_Unknown")
+	assert.False(t, ok, "Expected unknown size to be absent")
+
+	client := wrapper.NewAzureClient(nil, wrapper.DefaultBaseUrl)
+	assert.Same(t, catalog, client.VmSizeCatalog, "Expected clients to share the embedded catalog")
+}
+
+func TestLoadAzureVmSizeCatalog(t *testing.T) {
+	data := `{"version": "local", "sizes": [{"name": "Standard_Custom_8", "vCpu": 8, "memoryGb": 64, "tempDiskGb": 0, "architecture": "Arm64"}]}`
+
+	catalog, err := wrapper.LoadAzureVmSizeCatalog([]byte(data))
+	assert.NoError(t, err, "Expected local catalog to load")
+	assert.Equal(t, "local", catalog.Version)
+
+	vmSize, ok := catalog.GetVmSize("Standard_Custom_8")
+	assert.True(t, ok)
+	assert.Equal(t, "Arm64", vmSize.Architecture)
+
+	_, err = wrapper.LoadAzureVmSizeCatalog([]byte(`{"version": "1", "sizes": [{"name": "Standard_Zero"}]}`))
+	assert.Error(t, err, "Expected an error for an invalid entry")
+}
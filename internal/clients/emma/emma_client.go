@@ -2,35 +2,80 @@ package emma
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
 	ultron "github.com/be-heroes/ultron/pkg"
-	"github.com/cenkalti/backoff"
 	emma "github.com/emma-community/emma-go-sdk"
 )
 
 type IEmmaClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
 	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
 	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
 	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
 }
 
+const (
+	DefaultPageSize = 100
+	MaxPages        = 1000
+)
+
+// errUnauthorized is returned for configuration requests emma rejected the access token of.
+var errUnauthorized = errors.New("unauthorized")
+
 type EmmaClient struct {
 	client       *emma.APIClient
-	clientId     string
-	clientSecret string
+	tokenManager *EmmaTokenManager
+	catalogs     attendant.FetchCache[[]emma.VmConfiguration]
+	CatalogTtl   time.Duration
+	Filter       attendant.EmmaFilter
+}
+
+// emmaConfigsRequest is what the VM and spot configuration requests of the SDK have in common.
+type emmaConfigsRequest[R any] interface {
+	ProviderId(providerId int32) R
+	LocationId(locationId int32) R
+	VCpuMin(vCpuMin int32) R
+	VCpuMax(vCpuMax int32) R
+	RamGbMin(ramGbMin int32) R
+	RamGbMax(ramGbMax int32) R
+	VolumeGbMin(volumeGbMin int32) R
+	VolumeGbMax(volumeGbMax int32) R
+	PriceMin(priceMin float32) R
+	PriceMax(priceMax float32) R
+	Page(page int32) R
+	Size(size int32) R
+	Execute() (*emma.GetVmConfigs200Response, *http.Response, error)
 }
 
 func NewEmmaClient(clientId string, clientSecret string) *EmmaClient {
+	return NewEmmaClientWithConfiguration(emma.NewConfiguration(), clientId, clientSecret)
+}
+
+func NewEmmaClientWithConfiguration(configuration *emma.Configuration, clientId string, clientSecret string) *EmmaClient {
+	client := emma.NewAPIClient(configuration)
 	return &EmmaClient{
-		client:       emma.NewAPIClient(emma.NewConfiguration()),
-		clientId:     clientId,
-		clientSecret: clientSecret,
+		client:       client,
+		tokenManager: NewEmmaTokenManager(client, clientId, clientSecret),
+		CatalogTtl:   attendant.DefaultFetchCacheTtl,
 	}
 }
 
+func (ec *EmmaClient) GetName() string {
+	return attendant.ProviderNameEmma
+}
+
+func (ec *EmmaClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+}
+
 func (ec *EmmaClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
 	durableConfigs, err := ec.GetDurableComputeConfigurations(ctx)
 	if err != nil {
@@ -47,102 +92,186 @@ func (ec *EmmaClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultro
 	return &result, err
 }
 
+// GetDurableComputeConfigurations maps the VM catalog. The catalog is downloaded at most once per CatalogTtl,
+// however many callers ask for it.
 func (ec *EmmaClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
-	accessToken, err := ec.getAccessToken(ctx)
+	configs, err := ec.catalogs.Get(ctx, "durable", ec.CatalogTtl, ec.fetchVmConfigs)
 	if err != nil {
 		return nil, err
 	}
 
-	auth := context.WithValue(context.Background(), emma.ContextAccessToken, accessToken)
-	durableConfigs, resp, err := ec.client.ComputeInstancesConfigurationsAPI.GetVmConfigs(auth).Execute()
+	return ec.mapConfigurations(configs, ultron.ComputeTypeDurable), nil
+}
+
+// GetEphemeralComputeConfigurations maps the spot catalog. The catalog is downloaded at most once per
+// CatalogTtl, however many callers ask for it.
+func (ec *EmmaClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	configs, err := ec.catalogs.Get(ctx, "spot", ec.CatalogTtl, ec.fetchSpotConfigs)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+	return ec.mapConfigurations(configs, ultron.ComputeTypeEphemeral), nil
+}
+
+func (ec *EmmaClient) fetchVmConfigs(ctx context.Context) ([]emma.VmConfiguration, error) {
+	return ec.fetchWithToken(ctx, func(auth context.Context) ([]emma.VmConfiguration, error) {
+		return fetchPages(ec.client.ComputeInstancesConfigurationsAPI.GetVmConfigs(auth), ec.Filter, "durable")
+	})
+}
 
-		return nil, fmt.Errorf("failed to fetch durable configs: %v", string(body))
+func (ec *EmmaClient) fetchSpotConfigs(ctx context.Context) ([]emma.VmConfiguration, error) {
+	return ec.fetchWithToken(ctx, func(auth context.Context) ([]emma.VmConfiguration, error) {
+		return fetchPages(ec.client.ComputeInstancesConfigurationsAPI.GetSpotConfigs(auth), ec.Filter, "ephemeral")
+	})
+}
+
+// fetchWithToken attaches the cached access token to the fetch. A rejected token is invalidated and the fetch
+// retried once with a renewed one, since a cached token may have been revoked before it expired.
+func (ec *EmmaClient) fetchWithToken(ctx context.Context, fetch func(auth context.Context) ([]emma.VmConfiguration, error)) ([]emma.VmConfiguration, error) {
+	for attempt := 0; ; attempt++ {
+		accessToken, err := ec.getAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		configs, err := fetch(context.WithValue(ctx, emma.ContextAccessToken, accessToken))
+		if errors.Is(err, errUnauthorized) {
+			ec.tokenManager.Invalidate()
+
+			if attempt == 0 {
+				continue
+			}
+		}
+
+		return configs, err
+	}
+}
+
+// fetchPages lets emma apply the filter and reads the result page by page until the last one.
+func fetchPages[R emmaConfigsRequest[R]](request R, filter attendant.EmmaFilter, kind string) ([]emma.VmConfiguration, error) {
+	request = applyFilter(request, filter)
+
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
 	}
 
-	var result []ultron.ComputeConfiguration
+	var results []emma.VmConfiguration
+
+	for page := int32(0); page < MaxPages; page++ {
+		configs, resp, err := request.Page(page).Size(pageSize).Execute()
 
-	for _, config := range durableConfigs.Content {
-		result = append(result, ec.mapConfiguration(&config, ultron.ComputeTypeDurable))
+		content, err := checkConfigsResponse(configs, resp, err, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, content...)
+
+		if isLastPage(configs, page, pageSize) {
+			return results, nil
+		}
 	}
 
-	return &result, nil
+	return nil, fmt.Errorf("failed to fetch %s configs: more than %d pages", kind, MaxPages)
 }
 
-func (ec *EmmaClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
-	accessToken, err := ec.getAccessToken(ctx)
-	if err != nil {
-		return nil, err
+func applyFilter[R emmaConfigsRequest[R]](request R, filter attendant.EmmaFilter) R {
+	if filter.ProviderId > 0 {
+		request = request.ProviderId(filter.ProviderId)
 	}
 
-	auth := context.WithValue(context.Background(), emma.ContextAccessToken, accessToken)
-	durableConfigs, resp, err := ec.client.ComputeInstancesConfigurationsAPI.GetVmConfigs(auth).Execute()
-	if err != nil {
-		return nil, err
+	if filter.LocationId > 0 {
+		request = request.LocationId(filter.LocationId)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+	if filter.VCpuMin > 0 {
+		request = request.VCpuMin(filter.VCpuMin)
+	}
 
-		return nil, fmt.Errorf("failed to fetch ephemeral configs: %v", string(body))
+	if filter.VCpuMax > 0 {
+		request = request.VCpuMax(filter.VCpuMax)
 	}
 
-	ephemeralConfigs, resp, err := ec.client.ComputeInstancesConfigurationsAPI.GetSpotConfigs(auth).Execute()
-	if err != nil {
-		return nil, err
+	if filter.RamGbMin > 0 {
+		request = request.RamGbMin(filter.RamGbMin)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
+	if filter.RamGbMax > 0 {
+		request = request.RamGbMax(filter.RamGbMax)
+	}
 
-		return nil, fmt.Errorf("failed to fetch ephemeral configs: %v", string(body))
+	if filter.VolumeGbMin > 0 {
+		request = request.VolumeGbMin(filter.VolumeGbMin)
 	}
 
-	var result []ultron.ComputeConfiguration
+	if filter.VolumeGbMax > 0 {
+		request = request.VolumeGbMax(filter.VolumeGbMax)
+	}
 
-	for _, config := range durableConfigs.Content {
-		result = append(result, ec.mapConfiguration(&config, ultron.ComputeTypeDurable))
+	if filter.PriceMin > 0 {
+		request = request.PriceMin(filter.PriceMin)
 	}
 
-	for _, config := range ephemeralConfigs.Content {
-		result = append(result, ec.mapConfiguration(&config, ultron.ComputeTypeEphemeral))
+	if filter.PriceMax > 0 {
+		request = request.PriceMax(filter.PriceMax)
 	}
 
-	return &result, nil
+	return request
 }
 
-func (ec *EmmaClient) getAccessToken(ctx context.Context) (string, error) {
-	credentials := emma.Credentials{ClientId: ec.clientId, ClientSecret: ec.clientSecret}
-	var token string
-	var err error
+// isLastPage trusts the page metadata when emma sends it and otherwise stops at the first page that is not full.
+func isLastPage(configs *emma.GetVmConfigs200Response, page int32, pageSize int32) bool {
+	if len(configs.Content) == 0 {
+		return true
+	}
 
-	operation := func() error {
-		tokenResp, _, err := ec.client.AuthenticationAPI.IssueToken(ctx).Credentials(credentials).Execute()
-		if err != nil {
-			return err
-		}
+	if configs.Last != nil {
+		return *configs.Last
+	}
 
-		token = tokenResp.GetAccessToken()
+	if configs.TotalPages != nil {
+		return page+1 >= *configs.TotalPages
+	}
 
-		return nil
+	return int32(len(configs.Content)) < pageSize
+}
+
+func checkConfigsResponse(configs *emma.GetVmConfigs200Response, resp *http.Response, err error, kind string) ([]emma.VmConfiguration, error) {
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("failed to fetch %s configs: %w", kind, errUnauthorized)
+	}
+
+	if resp != nil && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		return nil, fmt.Errorf("failed to fetch %s configs: %v", kind, string(body))
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	backoffStrategy := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3)
+	return configs.Content, nil
+}
+
+func (ec *EmmaClient) mapConfigurations(configs []emma.VmConfiguration, computeType ultron.ComputeType) *[]ultron.ComputeConfiguration {
+	result := []ultron.ComputeConfiguration{}
 
-	if err = backoff.Retry(operation, backoffStrategy); err != nil {
-		return "", err
+	for _, config := range configs {
+		result = append(result, ec.mapConfiguration(&config, computeType))
 	}
 
-	return token, nil
+	return &result
+}
+
+func (ec *EmmaClient) getAccessToken(ctx context.Context) (string, error) {
+	return ec.tokenManager.GetAccessToken(ctx)
 }
 
 func (ec *EmmaClient) mapConfiguration(config *emma.VmConfiguration, computeType ultron.ComputeType) ultron.ComputeConfiguration {
-	return ultron.ComputeConfiguration{
+	configuration := ultron.ComputeConfiguration{
 		Identifier:        toStringPointer(config.Id),
 		Provider:          config.ProviderName,
 		Location:          config.LocationName,
@@ -155,13 +284,18 @@ func (ec *EmmaClient) mapConfiguration(config *emma.VmConfiguration, computeType
 		RamGb:             toInt64Pointer(config.RamGb),
 		VolumeGb:          toInt64Pointer(config.VolumeGb),
 		VolumeType:        config.VolumeType,
-		Cost: &ultron.ComputeCost{
+		ComputeType:       computeType,
+	}
+
+	if config.Cost != nil {
+		configuration.Cost = &ultron.ComputeCost{
 			Unit:         config.Cost.Unit,
 			Currency:     config.Cost.Currency,
 			PricePerUnit: toFloat64Pointer(config.Cost.PricePerUnit),
-		},
-		ComputeType: computeType,
+		}
 	}
+
+	return configuration
 }
 
 func toStringPointer(value *int32) *string {
@@ -169,7 +303,7 @@ func toStringPointer(value *int32) *string {
 		return nil
 	}
 
-	v := string(*value)
+	v := strconv.Itoa(int(*value))
 
 	return &v
 }
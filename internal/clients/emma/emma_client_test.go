@@ -0,0 +1,224 @@
+package emma_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/emma"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	emma "github.com/emma-community/emma-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+type catalogServer struct {
+	*httptest.Server
+	vmRequests    int32
+	spotRequests  int32
+	tokenRequests int32
+	failVms       int32
+	rejectVms     int32
+}
+
+func newCatalogServer(t *testing.T) *catalogServer {
+	server := &catalogServer{}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/issue-token":
+			atomic.AddInt32(&server.tokenRequests, 1)
+			fmt.Fprint(w, `{"accessToken": "token", "expiresIn": 600}`)
+		case "/v1/vms-configs":
+			atomic.AddInt32(&server.vmRequests, 1)
+			time.Sleep(20 * time.Millisecond)
+
+			if atomic.AddInt32(&server.rejectVms, -1) >= 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			if atomic.AddInt32(&server.failVms, -1) >= 0 {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"message": "unavailable"}`)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"content": [
+				{"id": 101, "providerName": "AWS", "locationName": "Stockholm", "vCpu": 2, "ramGb": 8, "cost": {"unit": "HOURS", "currency": "EUR", "pricePerUnit": 0.1}},
+				{"id": 102, "providerName": "Azure", "locationName": "Amsterdam", "vCpu": 4, "ramGb": 16}
+			]}`)
+		case "/v1/spots-configs":
+			atomic.AddInt32(&server.spotRequests, 1)
+			time.Sleep(20 * time.Millisecond)
+
+			fmt.Fprint(w, `{"content": [
+				{"id": 201, "providerName": "AWS", "locationName": "Stockholm", "vCpu": 2, "ramGb": 8, "cost": {"unit": "HOURS", "currency": "EUR", "pricePerUnit": 0.03}}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newEmmaClient(server *catalogServer) *wrapper.EmmaClient {
+	configuration := emma.NewConfiguration()
+	configuration.Servers = emma.ServerConfigurations{{URL: server.URL}}
+
+	return wrapper.NewEmmaClientWithConfiguration(configuration, "client-id", "client-secret")
+}
+
+func TestGetComputeConfigurationsFetchesEachCatalogOnce(t *testing.T) {
+	server := newCatalogServer(t)
+	client := newEmmaClient(server)
+	assert.Equal(t, attendant.DefaultFetchCacheTtl, client.CatalogTtl)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			configs, err := client.GetDurableComputeConfigurations(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 2, len(*configs))
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			configs, err := client.GetEphemeralComputeConfigurations(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(*configs), "Expected only spot configs in the ephemeral view")
+		}()
+	}
+
+	wg.Wait()
+
+	configs, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(*configs))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.vmRequests), "Expected the VM catalog to be downloaded once")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.spotRequests), "Expected the spot catalog to be downloaded once")
+
+	durable := (*configs)[0]
+	assert.Equal(t, "101", *durable.Identifier)
+	assert.Equal(t, ultron.ComputeTypeDurable, durable.ComputeType)
+	assert.Equal(t, int64(2), *durable.VCpu)
+	assert.Equal(t, "EUR", *durable.Cost.Currency)
+	assert.Nil(t, (*configs)[1].Cost, "Expected a config without cost to be mapped without one")
+
+	ephemeral := (*configs)[2]
+	assert.Equal(t, "201", *ephemeral.Identifier)
+	assert.Equal(t, ultron.ComputeTypeEphemeral, ephemeral.ComputeType)
+}
+
+func TestGetComputeConfigurationsRefetchesAfterTtl(t *testing.T) {
+	server := newCatalogServer(t)
+	client := newEmmaClient(server)
+	client.CatalogTtl = 0
+
+	_, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+
+	_, err = client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.vmRequests))
+}
+
+func TestGetComputeConfigurationsDoesNotKeepFailures(t *testing.T) {
+	server := newCatalogServer(t)
+	server.failVms = 1
+	client := newEmmaClient(server)
+
+	_, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.EqualError(t, err, `failed to fetch durable configs: {"message": "unavailable"}`)
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected a failed download to be retried")
+	assert.Equal(t, 2, len(*configs))
+}
+
+func TestGetComputeConfigurationsFiltersAndPages(t *testing.T) {
+	var queries []url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/issue-token":
+			fmt.Fprint(w, `{"accessToken": "token", "expiresIn": 600}`)
+		case "/v1/vms-configs":
+			queries = append(queries, r.URL.Query())
+
+			switch r.URL.Query().Get("page") {
+			case "0":
+				fmt.Fprint(w, `{"content": [{"id": 1}, {"id": 2}], "number": 0, "totalPages": 2, "last": false}`)
+			default:
+				fmt.Fprint(w, `{"content": [{"id": 3}], "number": 1, "totalPages": 2, "last": true}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	configuration := emma.NewConfiguration()
+	configuration.Servers = emma.ServerConfigurations{{URL: server.URL}}
+
+	client := wrapper.NewEmmaClientWithConfiguration(configuration, "client-id", "client-secret")
+	client.Filter = attendant.EmmaFilter{ProviderId: 3, LocationId: 7, VCpuMin: 2, RamGbMax: 64, PriceMax: 0.5, PageSize: 2}
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(*configs), "Expected the configs of both pages")
+	assert.Equal(t, "3", *(*configs)[2].Identifier)
+
+	assert.Equal(t, 2, len(queries))
+	assert.Equal(t, "1", queries[1].Get("page"))
+
+	for _, query := range queries {
+		assert.Equal(t, "3", query.Get("providerId"))
+		assert.Equal(t, "7", query.Get("locationId"))
+		assert.Equal(t, "2", query.Get("vCpuMin"))
+		assert.Equal(t, "64", query.Get("ramGbMax"))
+		assert.Equal(t, "0.5", query.Get("priceMax"))
+		assert.Equal(t, "2", query.Get("size"))
+		assert.False(t, query.Has("vCpuMax"), "Expected unset filters to be left out")
+	}
+}
+
+func TestGetComputeConfigurationsRenewsRejectedToken(t *testing.T) {
+	server := newCatalogServer(t)
+	server.rejectVms = 1
+	client := newEmmaClient(server)
+	client.CatalogTtl = 0
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected a rejected token to be renewed")
+	assert.Equal(t, 2, len(*configs))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.tokenRequests))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.vmRequests))
+
+	server.rejectVms = 2
+
+	_, err = client.GetDurableComputeConfigurations(context.Background())
+	assert.ErrorContains(t, err, "failed to fetch durable configs: unauthorized", "Expected the fetch to be retried once")
+	assert.Equal(t, int32(4), atomic.LoadInt32(&server.vmRequests))
+}
@@ -0,0 +1,124 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	emma "github.com/emma-community/emma-go-sdk"
+)
+
+const (
+	TokenExpirySkew      = 30 * time.Second
+	DefaultTokenLifetime = 10 * time.Minute
+)
+
+// EmmaTokenManager caches the access token until shortly before it expires and renews it with the refresh
+// token while that is still valid, falling back to issuing a new token from the client credentials. Callers
+// that arrive while a token is being fetched wait for it instead of fetching their own.
+type EmmaTokenManager struct {
+	client           *emma.APIClient
+	credentials      emma.Credentials
+	mutex            sync.Mutex
+	accessToken      string
+	expiresAt        time.Time
+	refreshToken     string
+	refreshExpiresAt time.Time
+	now              func() time.Time
+}
+
+func NewEmmaTokenManager(client *emma.APIClient, clientId string, clientSecret string) *EmmaTokenManager {
+	return &EmmaTokenManager{
+		client:      client,
+		credentials: emma.Credentials{ClientId: clientId, ClientSecret: clientSecret},
+		now:         time.Now,
+	}
+}
+
+func (m *EmmaTokenManager) GetAccessToken(ctx context.Context) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.accessToken != "" && m.now().Before(m.expiresAt) {
+		return m.accessToken, nil
+	}
+
+	if m.refreshToken != "" && m.now().Before(m.refreshExpiresAt) {
+		token, _, err := m.client.AuthenticationAPI.RefreshToken(ctx).RefreshToken(emma.RefreshToken{RefreshToken: m.refreshToken}).Execute()
+		if err == nil && token.GetAccessToken() != "" {
+			m.setToken(token)
+
+			return m.accessToken, nil
+		}
+	}
+
+	token, err := m.issueToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	m.setToken(token)
+
+	return m.accessToken, nil
+}
+
+// Invalidate drops the cached access token, e.g. after the API rejected it, while keeping the refresh token.
+func (m *EmmaTokenManager) Invalidate() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.accessToken = ""
+}
+
+func (m *EmmaTokenManager) issueToken(ctx context.Context) (*emma.Token, error) {
+	var token *emma.Token
+
+	operation := func() error {
+		tokenResp, resp, err := m.client.AuthenticationAPI.IssueToken(ctx).Credentials(m.credentials).Execute()
+		if err != nil {
+			if resp != nil && resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError {
+				return backoff.Permanent(fmt.Errorf("failed to issue token: %v", err))
+			}
+
+			return err
+		}
+
+		if tokenResp.GetAccessToken() == "" {
+			return backoff.Permanent(fmt.Errorf("failed to issue token: response has no access token"))
+		}
+
+		token = tokenResp
+
+		return nil
+	}
+
+	backoffStrategy := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3), ctx)
+
+	if err := backoff.Retry(operation, backoffStrategy); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (m *EmmaTokenManager) setToken(token *emma.Token) {
+	now := m.now()
+
+	lifetime := DefaultTokenLifetime
+	if token.GetExpiresIn() > 0 {
+		lifetime = time.Duration(token.GetExpiresIn()) * time.Second
+	}
+
+	m.accessToken = token.GetAccessToken()
+	m.expiresAt = now.Add(lifetime - TokenExpirySkew)
+
+	if token.GetRefreshToken() != "" && token.GetRefreshExpiresIn() > 0 {
+		m.refreshToken = token.GetRefreshToken()
+		m.refreshExpiresAt = now.Add(time.Duration(token.GetRefreshExpiresIn())*time.Second - TokenExpirySkew)
+	} else {
+		m.refreshToken = ""
+	}
+}
@@ -0,0 +1,150 @@
+package emma_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/emma"
+	emma "github.com/emma-community/emma-go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+type authServer struct {
+	*httptest.Server
+	issued        int32
+	refreshed     int32
+	refreshTokens []string
+}
+
+func newAuthServer(t *testing.T, expiresIn int, refreshExpiresIn int) *authServer {
+	server := &authServer{}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v1/issue-token":
+			var credentials emma.Credentials
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&credentials))
+
+			if credentials.ClientSecret != "client-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"message": "invalid credentials"}`)
+
+				return
+			}
+
+			count := atomic.AddInt32(&server.issued, 1)
+			fmt.Fprintf(w, `{"accessToken": "issued-%d", "expiresIn": %d, "refreshToken": "refresh-%d", "refreshExpiresIn": %d}`, count, expiresIn, count, refreshExpiresIn)
+		case "/v1/refresh-token":
+			var refreshToken emma.RefreshToken
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&refreshToken))
+
+			server.refreshTokens = append(server.refreshTokens, refreshToken.RefreshToken)
+			count := atomic.AddInt32(&server.refreshed, 1)
+			fmt.Fprintf(w, `{"accessToken": "refreshed-%d", "expiresIn": %d, "refreshToken": "refresh-r%d", "refreshExpiresIn": %d}`, count, expiresIn, count, refreshExpiresIn)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTokenManager(server *authServer, clientSecret string) *wrapper.EmmaTokenManager {
+	configuration := emma.NewConfiguration()
+	configuration.Servers = emma.ServerConfigurations{{URL: server.URL}}
+
+	return wrapper.NewEmmaTokenManager(emma.NewAPIClient(configuration), "client-id", clientSecret)
+}
+
+func TestGetAccessTokenIsCached(t *testing.T) {
+	server := newAuthServer(t, 600, 1800)
+	manager := newTokenManager(server, "client-secret")
+
+	for i := 0; i < 3; i++ {
+		token, err := manager.GetAccessToken(context.Background())
+		assert.NoError(t, err, "Expected no error from GetAccessToken")
+		assert.Equal(t, "issued-1", token)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.issued), "Expected a single token to be issued")
+}
+
+func TestGetAccessTokenUsesRefreshToken(t *testing.T) {
+	server := newAuthServer(t, 1, 1800)
+	manager := newTokenManager(server, "client-secret")
+
+	token, err := manager.GetAccessToken(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "issued-1", token)
+
+	token, err = manager.GetAccessToken(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed-1", token, "Expected an expired token to be refreshed")
+
+	token, err = manager.GetAccessToken(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed-2", token)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.issued))
+	assert.Equal(t, []string{"refresh-1", "refresh-r1"}, server.refreshTokens, "Expected the latest refresh token to be used")
+}
+
+func TestGetAccessTokenIssuesWhenRefreshTokenExpired(t *testing.T) {
+	server := newAuthServer(t, 1, 1)
+	manager := newTokenManager(server, "client-secret")
+
+	_, err := manager.GetAccessToken(context.Background())
+	assert.NoError(t, err)
+
+	token, err := manager.GetAccessToken(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "issued-2", token)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&server.refreshed))
+}
+
+func TestGetAccessTokenConcurrentCallers(t *testing.T) {
+	server := newAuthServer(t, 600, 1800)
+	manager := newTokenManager(server, "client-secret")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			token, err := manager.GetAccessToken(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "issued-1", token)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.issued), "Expected concurrent callers to share a token")
+
+	manager.Invalidate()
+
+	token, err := manager.GetAccessToken(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed-1", token, "Expected an invalidated token to be refreshed")
+}
+
+func TestGetAccessTokenInvalidCredentials(t *testing.T) {
+	server := newAuthServer(t, 600, 1800)
+	manager := newTokenManager(server, "wrong")
+
+	_, err := manager.GetAccessToken(context.Background())
+	assert.Error(t, err, "Expected invalid credentials to fail")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&server.issued))
+}
@@ -0,0 +1,216 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+)
+
+const (
+	DefaultBillingLookbackDays = 7
+
+	BillingExportTableStandardPrefix = "gcp_billing_export_v1_"
+	BillingExportTableDetailedPrefix = "gcp_billing_export_resource_v1_"
+)
+
+var (
+	projectIdPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	datasetIdPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	tableIdPattern   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+type IBillingQueryRunner interface {
+	Query(ctx context.Context, query string, parameters []bigquery.QueryParameter) (IBillingRowIterator, error)
+}
+
+type IBillingRowIterator interface {
+	Next(dst interface{}) error
+}
+
+type BigQueryRunner struct {
+	client *bigquery.Client
+}
+
+func NewBigQueryRunner(client *bigquery.Client) *BigQueryRunner {
+	return &BigQueryRunner{
+		client: client,
+	}
+}
+
+func (r *BigQueryRunner) Query(ctx context.Context, query string, parameters []bigquery.QueryParameter) (IBillingRowIterator, error) {
+	q := r.client.Query(query)
+	q.Parameters = parameters
+
+	return q.Read(ctx)
+}
+
+// GcpBillingTable identifies a Cloud Billing export table. Detailed (resource-level) exports carry the
+// same columns as standard exports plus resource identifiers.
+type GcpBillingTable struct {
+	ProjectId string
+	DatasetId string
+	TableId   string
+}
+
+type GcpBillingRow struct {
+	ServiceName    string              `bigquery:"service_name"`
+	SkuName        string              `bigquery:"sku_name"`
+	ResourceName   bigquery.NullString `bigquery:"resource_name"`
+	Region         bigquery.NullString `bigquery:"region"`
+	MachineType    bigquery.NullString `bigquery:"machine_type"`
+	UsageStartTime time.Time           `bigquery:"usage_start_time"`
+	UsageEndTime   time.Time           `bigquery:"usage_end_time"`
+	UsageAmount    float64             `bigquery:"usage_amount"`
+	UsageUnit      string              `bigquery:"usage_unit"`
+	Cost           float64             `bigquery:"cost"`
+	Credits        float64             `bigquery:"credits"`
+	Currency       string              `bigquery:"currency"`
+}
+
+func NewGcpBillingTable(config *attendant.Config) (*GcpBillingTable, error) {
+	table := &GcpBillingTable{
+		ProjectId: config.GcpBillingProjectId,
+		DatasetId: config.GcpBillingDatasetId,
+		TableId:   config.GcpBillingTableId,
+	}
+
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+func (t *GcpBillingTable) Validate() error {
+	if !projectIdPattern.MatchString(t.ProjectId) {
+		return fmt.Errorf("invalid GCP billing project id: %q", t.ProjectId)
+	}
+
+	if !datasetIdPattern.MatchString(t.DatasetId) {
+		return fmt.Errorf("invalid GCP billing dataset id: %q", t.DatasetId)
+	}
+
+	if !tableIdPattern.MatchString(t.TableId) {
+		return fmt.Errorf("invalid GCP billing table id: %q", t.TableId)
+	}
+
+	return nil
+}
+
+func (t *GcpBillingTable) IsDetailed() bool {
+	return strings.HasPrefix(t.TableId, BillingExportTableDetailedPrefix)
+}
+
+// String renders the fully qualified table reference. The identifiers are validated, which is what makes
+// interpolating them into a query safe, since BigQuery does not accept table names as query parameters.
+func (t *GcpBillingTable) String() string {
+	return fmt.Sprintf("`%s.%s.%s`", t.ProjectId, t.DatasetId, t.TableId)
+}
+
+type effectiveCostKey struct {
+	machineType string
+	region      string
+	date        time.Time
+	currency    string
+}
+
+// aggregateBillingRows sums cost and credits per machine type, region, day and currency. Instance hours are
+// derived from core usage, which is billed in vCPU-seconds, so that RAM rows only contribute cost. Rows
+// without a machine type, such as disks and network, are skipped.
+func aggregateBillingRows(rows []GcpBillingRow, catalog *GcpMachineTypeCatalog) []attendant.EffectiveComputeCost {
+	costs := make(map[effectiveCostKey]*attendant.EffectiveComputeCost)
+
+	var keys []effectiveCostKey
+
+	for _, row := range rows {
+		if !row.MachineType.Valid || row.MachineType.StringVal == "" {
+			continue
+		}
+
+		key := effectiveCostKey{
+			machineType: row.MachineType.StringVal,
+			region:      row.Region.StringVal,
+			date:        row.UsageStartTime.UTC().Truncate(24 * time.Hour),
+			currency:    row.Currency,
+		}
+
+		cost, ok := costs[key]
+		if !ok {
+			cost = &attendant.EffectiveComputeCost{
+				Provider:   attendant.ProviderNameGcp,
+				Identifier: key.machineType,
+				Location:   key.region,
+				Date:       key.date,
+				Currency:   key.currency,
+			}
+			costs[key] = cost
+			keys = append(keys, key)
+		}
+
+		cost.Cost += row.Cost
+		cost.Credits += row.Credits
+		cost.UsageHours += getInstanceHours(&row, catalog)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].date.Equal(keys[j].date) {
+			return keys[i].date.Before(keys[j].date)
+		}
+
+		if keys[i].machineType != keys[j].machineType {
+			return keys[i].machineType < keys[j].machineType
+		}
+
+		return keys[i].region < keys[j].region
+	})
+
+	var results []attendant.EffectiveComputeCost
+
+	for _, key := range keys {
+		cost := costs[key]
+		if cost.UsageHours <= 0 {
+			continue
+		}
+
+		cost.EffectiveHourlyRate = (cost.Cost + cost.Credits) / cost.UsageHours
+
+		results = append(results, *cost)
+	}
+
+	return results
+}
+
+func getInstanceHours(row *GcpBillingRow, catalog *GcpMachineTypeCatalog) float64 {
+	if row.UsageUnit != "seconds" {
+		return 0
+	}
+
+	_, resource, _, ok := parseSkuDescription(row.SkuName)
+	if !ok {
+		return 0
+	}
+
+	switch resource {
+	case ResourceTypeInstance:
+		return row.UsageAmount / 3600
+	case ResourceTypeCore:
+		if catalog == nil {
+			return 0
+		}
+
+		machineType, ok := catalog.GetMachineType(row.MachineType.StringVal)
+		if !ok || machineType.GetBilledVCpu() <= 0 {
+			return 0
+		}
+
+		return row.UsageAmount / machineType.GetBilledVCpu() / 3600
+	}
+
+	return 0
+}
@@ -0,0 +1,275 @@
+package gcp
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"google.golang.org/api/cloudbilling/v1"
+)
+
+type GcpUsageType string
+type GcpResourceType string
+
+const (
+	ComputeEngineServiceName = "services/6F81-5844-456A"
+
+	ResourceTypeCore     GcpResourceType = "core"
+	ResourceTypeRam      GcpResourceType = "ram"
+	ResourceTypeInstance GcpResourceType = "instance"
+
+	UsageTypeOnDemand    GcpUsageType = "OnDemand"
+	UsageTypePreemptible GcpUsageType = "Preemptible"
+	UsageTypeCommit1Yr   GcpUsageType = "Commit1Yr"
+	UsageTypeCommit3Yr   GcpUsageType = "Commit3Yr"
+)
+
+// GcpSkuPrice is the per-region price of one vCPU-hour or one GiB-hour of a machine family, or of one
+// instance-hour for the f1 and g1 shared-core families.
+type GcpSkuPrice struct {
+	SkuId        string
+	Description  string
+	Family       string
+	Resource     GcpResourceType
+	Custom       bool
+	Region       string
+	UsageType    GcpUsageType
+	Currency     string
+	Unit         string
+	PricePerUnit float64
+}
+
+// parseSku extracts core and RAM prices from a Compute Engine SKU. SKUs are only distinguishable by their
+// description ("N2 Instance Core running in Americas", "Spot Preemptible N2 Instance Ram running in Paris",
+// "Commitment v1: N2 Cpu in Americas for 1 Year"), so anything that does not parse as a core or RAM price of
+// a machine family, such as GPUs, licenses, sole tenancy and extended memory, is skipped.
+func parseSku(sku *cloudbilling.Sku) ([]GcpSkuPrice, bool) {
+	if sku.Category == nil || sku.Category.ResourceFamily != "Compute" {
+		return nil, false
+	}
+
+	usageType := GcpUsageType(sku.Category.UsageType)
+	if !slices.Contains([]GcpUsageType{UsageTypeOnDemand, UsageTypePreemptible, UsageTypeCommit1Yr, UsageTypeCommit3Yr}, usageType) {
+		return nil, false
+	}
+
+	family, resource, custom, ok := parseSkuDescription(sku.Description)
+	if !ok {
+		return nil, false
+	}
+
+	currency, unit, price, ok := parsePricingInfo(sku.PricingInfo)
+	if !ok {
+		return nil, false
+	}
+
+	var prices []GcpSkuPrice
+
+	for _, region := range sku.ServiceRegions {
+		prices = append(prices, GcpSkuPrice{
+			SkuId:        sku.SkuId,
+			Description:  sku.Description,
+			Family:       family,
+			Resource:     resource,
+			Custom:       custom,
+			Region:       region,
+			UsageType:    usageType,
+			Currency:     currency,
+			Unit:         unit,
+			PricePerUnit: price,
+		})
+	}
+
+	return prices, len(prices) > 0
+}
+
+func parseSkuDescription(description string) (family string, resource GcpResourceType, custom bool, ok bool) {
+	normalized := strings.ToLower(description)
+
+	for _, excluded := range []string{"sole tenancy", "extended", "premium", "gpu", "licens"} {
+		if strings.Contains(normalized, excluded) {
+			return "", "", false, false
+		}
+	}
+
+	for _, prefix := range []string{"spot preemptible ", "preemptible ", "commitment v1: "} {
+		normalized = strings.TrimPrefix(normalized, prefix)
+	}
+
+	switch {
+	case strings.HasPrefix(normalized, "micro instance with burstable cpu"):
+		return "f1", ResourceTypeInstance, false, true
+	case strings.HasPrefix(normalized, "small instance with 1 vcpu"):
+		return "g1", ResourceTypeInstance, false, true
+	}
+
+	var familyWords []string
+
+	for _, word := range strings.Fields(normalized) {
+		switch word {
+		case "core", "cpu":
+			resource = ResourceTypeCore
+		case "ram":
+			resource = ResourceTypeRam
+		case "instance", "predefined", "amd", "arm", "intel":
+			continue
+		case "custom":
+			custom = true
+
+			continue
+		default:
+			familyWords = append(familyWords, word)
+
+			continue
+		}
+
+		break
+	}
+
+	if resource == "" {
+		return "", "", false, false
+	}
+
+	switch strings.Join(familyWords, " ") {
+	case "":
+		family = "n1"
+	case "compute optimized":
+		family = "c2"
+	case "memory-optimized", "memory optimized":
+		family = "m1"
+	default:
+		if len(familyWords) != 1 {
+			return "", "", false, false
+		}
+
+		family = familyWords[0]
+	}
+
+	return family, resource, custom, true
+}
+
+// parsePricingInfo reads the current price, which is the last tier of the first pricing info. Earlier tiers
+// are free usage allowances.
+func parsePricingInfo(pricingInfo []*cloudbilling.PricingInfo) (currency string, unit string, price float64, ok bool) {
+	if len(pricingInfo) == 0 || pricingInfo[0].PricingExpression == nil {
+		return "", "", 0, false
+	}
+
+	expression := pricingInfo[0].PricingExpression
+	if len(expression.TieredRates) == 0 {
+		return "", "", 0, false
+	}
+
+	rate := expression.TieredRates[len(expression.TieredRates)-1]
+	if rate.UnitPrice == nil {
+		return "", "", 0, false
+	}
+
+	price = float64(rate.UnitPrice.Units) + float64(rate.UnitPrice.Nanos)/1e9
+
+	return rate.UnitPrice.CurrencyCode, expression.UsageUnit, price, true
+}
+
+type gcpPriceKey struct {
+	family    string
+	region    string
+	usageType GcpUsageType
+	resource  GcpResourceType
+	custom    bool
+}
+
+// GcpPriceTable indexes SKU prices by machine family, region, usage type and resource so machine type prices
+// can be synthesized from them.
+type GcpPriceTable struct {
+	prices map[gcpPriceKey]GcpSkuPrice
+}
+
+func NewGcpPriceTable(prices []GcpSkuPrice) *GcpPriceTable {
+	table := &GcpPriceTable{
+		prices: make(map[gcpPriceKey]GcpSkuPrice),
+	}
+
+	for _, price := range prices {
+		table.prices[gcpPriceKey{
+			family:    price.Family,
+			region:    price.Region,
+			usageType: price.UsageType,
+			resource:  price.Resource,
+			custom:    price.Custom,
+		}] = price
+	}
+
+	return table
+}
+
+// GetRegions returns the regions, in order, that have any price for the family and usage type.
+func (t *GcpPriceTable) GetRegions(family string, usageType GcpUsageType) []string {
+	var regions []string
+
+	for key := range t.prices {
+		if key.family == family && key.usageType == usageType && !slices.Contains(regions, key.region) {
+			regions = append(regions, key.region)
+		}
+	}
+
+	sort.Strings(regions)
+
+	return regions
+}
+
+// GetMachineTypeCost prices a machine type as its billed vCPUs times the core price plus its memory times the
+// RAM price. Custom machine types use the custom SKUs of their family where those exist, and the f1 and g1
+// shared-core families are priced per instance.
+func (t *GcpPriceTable) GetMachineTypeCost(machineType *GcpMachineType, region string, usageType GcpUsageType) (*ultron.ComputeCost, error) {
+	unit := attendant.CostUnitHours
+
+	if instancePrice, ok := t.getPrice(machineType.Family, region, usageType, ResourceTypeInstance, false); ok {
+		return &ultron.ComputeCost{
+			Unit:         &unit,
+			Currency:     &instancePrice.Currency,
+			PricePerUnit: &instancePrice.PricePerUnit,
+		}, nil
+	}
+
+	corePrice, ok := t.getPrice(machineType.Family, region, usageType, ResourceTypeCore, machineType.Custom)
+	if !ok {
+		return nil, fmt.Errorf("no %s core price for %s in %s", usageType, machineType.Name, region)
+	}
+
+	ramPrice, ok := t.getPrice(machineType.Family, region, usageType, ResourceTypeRam, machineType.Custom)
+	if !ok {
+		return nil, fmt.Errorf("no %s ram price for %s in %s", usageType, machineType.Name, region)
+	}
+
+	if corePrice.Currency != ramPrice.Currency {
+		return nil, fmt.Errorf("core and ram prices for %s in %s differ in currency", machineType.Name, region)
+	}
+
+	price := machineType.GetBilledVCpu()*corePrice.PricePerUnit + machineType.MemoryGb*ramPrice.PricePerUnit
+
+	return &ultron.ComputeCost{
+		Unit:         &unit,
+		Currency:     &corePrice.Currency,
+		PricePerUnit: &price,
+	}, nil
+}
+
+func (t *GcpPriceTable) getPrice(family string, region string, usageType GcpUsageType, resource GcpResourceType, custom bool) (GcpSkuPrice, bool) {
+	key := gcpPriceKey{family: family, region: region, usageType: usageType, resource: resource, custom: custom}
+
+	if price, ok := t.prices[key]; ok {
+		return price, true
+	}
+
+	if !custom {
+		return GcpSkuPrice{}, false
+	}
+
+	key.custom = false
+	price, ok := t.prices[key]
+
+	return price, ok
+}
@@ -0,0 +1,265 @@
+package gcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/gcp"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/cloudbilling/v1"
+	"google.golang.org/api/option"
+)
+
+func newSku(id string, description string, usageType string, unit string, units int64, nanos int64, regions ...string) *cloudbilling.Sku {
+	return &cloudbilling.Sku{
+		SkuId:       id,
+		Description: description,
+		Category: &cloudbilling.Category{
+			ResourceFamily: "Compute",
+			ResourceGroup:  "CPU",
+			UsageType:      usageType,
+		},
+		ServiceRegions: regions,
+		PricingInfo: []*cloudbilling.PricingInfo{
+			{
+				PricingExpression: &cloudbilling.PricingExpression{
+					UsageUnit: unit,
+					TieredRates: []*cloudbilling.TierRate{
+						{UnitPrice: &cloudbilling.Money{CurrencyCode: "USD"}},
+						{StartUsageAmount: 1, UnitPrice: &cloudbilling.Money{CurrencyCode: "USD", Units: units, Nanos: nanos}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newCatalogClient(t *testing.T, pages ...[]*cloudbilling.Sku) *wrapper.GcpClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/"+wrapper.ComputeEngineServiceName+"/skus", r.URL.Path)
+
+		page := 0
+		if token := r.URL.Query().Get("pageToken"); token != "" {
+			page = int(token[0] - '0')
+		}
+
+		resp := cloudbilling.ListSkusResponse{Skus: pages[page]}
+		if page+1 < len(pages) {
+			resp.NextPageToken = string(rune('0' + page + 1))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	service, err := cloudbilling.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	assert.NoError(t, err, "Expected the billing service to be created")
+
+	return &wrapper.GcpClient{BillingService: service}
+}
+
+func TestGetSkuPrices(t *testing.T) {
+	client := newCatalogClient(t,
+		[]*cloudbilling.Sku{
+			newSku("1", "N2 Instance Core running in Americas", "OnDemand", "h", 0, 31611000, "us-central1", "us-east1"),
+			newSku("2", "N2 Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 4237000, "us-central1", "us-east1"),
+			newSku("3", "Nvidia Tesla T4 GPU running in Americas", "OnDemand", "h", 0, 350000000, "us-central1"),
+		},
+		[]*cloudbilling.Sku{
+			newSku("4", "Spot Preemptible N2 Instance Core running in Americas", "Preemptible", "h", 0, 7650000, "us-central1"),
+			newSku("5", "Commitment v1: N2 Cpu in Americas for 1 Year", "Commit1Yr", "h", 0, 19915000, "us-central1"),
+			newSku("6", "N2 Custom Instance Core running in Americas", "OnDemand", "h", 0, 33191000, "us-central1"),
+			newSku("7", "N2 Sole Tenancy Instance Core running in Americas", "OnDemand", "h", 0, 34772000, "us-central1"),
+		},
+	)
+	client.Regions = []string{"us-central1"}
+
+	prices, err := client.GetSkuPrices(context.Background())
+	assert.NoError(t, err, "Expected no error from GetSkuPrices")
+	assert.Equal(t, 5, len(*prices))
+
+	for _, price := range *prices {
+		assert.Equal(t, "n2", price.Family)
+		assert.Equal(t, "us-central1", price.Region)
+		assert.Equal(t, "USD", price.Currency)
+	}
+
+	assert.Equal(t, wrapper.ResourceTypeCore, (*prices)[0].Resource)
+	assert.Equal(t, wrapper.UsageTypeOnDemand, (*prices)[0].UsageType)
+	assert.InDelta(t, 0.031611, (*prices)[0].PricePerUnit, 1e-9)
+	assert.Equal(t, wrapper.ResourceTypeRam, (*prices)[1].Resource)
+	assert.Equal(t, "GiBy.h", (*prices)[1].Unit)
+	assert.Equal(t, wrapper.UsageTypePreemptible, (*prices)[2].UsageType)
+	assert.Equal(t, wrapper.UsageTypeCommit1Yr, (*prices)[3].UsageType)
+	assert.Equal(t, wrapper.ResourceTypeCore, (*prices)[3].Resource)
+	assert.True(t, (*prices)[4].Custom, "Expected a custom machine price")
+}
+
+func TestGetComputeConfigurationsFromCatalog(t *testing.T) {
+	client := newCatalogClient(t, []*cloudbilling.Sku{
+		newSku("1", "E2 Instance Core running in Americas", "OnDemand", "h", 0, 21811000, "us-central1"),
+		newSku("2", "E2 Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 2923000, "us-central1"),
+		newSku("3", "Spot Preemptible E2 Instance Core running in Americas", "Preemptible", "h", 0, 6543000, "us-central1"),
+		newSku("4", "Spot Preemptible E2 Instance Ram running in Americas", "Preemptible", "GiBy.h", 0, 877000, "us-central1"),
+		newSku("5", "N2 Instance Core running in Americas", "OnDemand", "h", 0, 31611000, "us-central1"),
+	})
+
+	catalog, err := wrapper.LoadGcpMachineTypeCatalog([]byte(`{"version": "test", "machineTypes": [
+		{"name": "e2-micro", "family": "e2", "vCpu": 2, "memoryGb": 1, "architecture": "x86_64", "sharedCore": true, "billedVCpu": 0.25},
+		{"name": "e2-standard-4", "family": "e2", "vCpu": 4, "memoryGb": 16, "architecture": "x86_64"},
+		{"name": "n2-standard-4", "family": "n2", "vCpu": 4, "memoryGb": 16, "architecture": "x86_64"}
+	]}`))
+	assert.NoError(t, err, "Expected the machine type catalog to load")
+	client.MachineTypeCatalog = catalog
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetDurableComputeConfigurations")
+	assert.Equal(t, 2, len(*configs), "Expected families without a RAM price to be skipped")
+
+	config := (*configs)[1]
+	assert.Equal(t, "e2-standard-4", *config.Identifier)
+	assert.Equal(t, attendant.ProviderNameGcp, *config.Provider)
+	assert.Equal(t, "us-central1", *config.Location)
+	assert.Equal(t, int64(4), *config.VCpu)
+	assert.Equal(t, int64(16), *config.RamGb)
+	assert.Equal(t, ultron.ComputeTypeDurable, config.ComputeType)
+	assert.Equal(t, attendant.CostUnitHours, *config.Cost.Unit)
+	assert.InDelta(t, 4*0.021811+16*0.002923, *config.Cost.PricePerUnit, 1e-9)
+
+	assert.Equal(t, "e2-micro", *(*configs)[0].Identifier)
+	assert.InDelta(t, 0.25*0.021811+0.002923, *(*configs)[0].Cost.PricePerUnit, 1e-9, "Expected shared-core types to be billed for a fraction of a vCPU")
+
+	configs, err = client.GetEphemeralComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetEphemeralComputeConfigurations")
+	assert.Equal(t, 2, len(*configs))
+	assert.Equal(t, ultron.ComputeTypeEphemeral, (*configs)[1].ComputeType)
+	assert.InDelta(t, 4*0.006543+16*0.000877, *(*configs)[1].Cost.PricePerUnit, 1e-9)
+
+	_, err = (&wrapper.GcpClient{}).GetSkuPrices(context.Background())
+	assert.EqualError(t, err, "GCP billing service is not configured")
+}
+
+func TestGetMachineTypeCostForLabels(t *testing.T) {
+	client := newCatalogClient(t, []*cloudbilling.Sku{
+		newSku("1", "N2 Instance Core running in Americas", "OnDemand", "h", 0, 31611000, "us-central1"),
+		newSku("2", "N2 Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 4237000, "us-central1"),
+		newSku("3", "N2 Custom Instance Core running in Americas", "OnDemand", "h", 0, 33191000, "us-central1"),
+		newSku("4", "N2 Custom Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 4449000, "us-central1"),
+		newSku("5", "Custom Instance Core running in Americas", "OnDemand", "h", 0, 33174000, "us-central1"),
+		newSku("6", "Custom Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 4446000, "us-central1"),
+		newSku("7", "Micro Instance with burstable CPU running in Americas", "OnDemand", "h", 0, 7600000, "us-central1"),
+	})
+
+	catalog, err := wrapper.NewGcpMachineTypeCatalog()
+	assert.NoError(t, err, "Expected the embedded machine type catalog to load")
+	client.MachineTypeCatalog = catalog
+
+	labels := map[string]string{
+		wrapper.LabelInstanceType:   "n2-standard-8",
+		wrapper.LabelTopologyRegion: "us-central1",
+	}
+
+	cost, err := client.GetMachineTypeCostForLabels(context.Background(), labels, wrapper.UsageTypeOnDemand)
+	assert.NoError(t, err, "Expected no error from GetMachineTypeCostForLabels")
+	assert.Equal(t, "USD", *cost.Currency)
+	assert.InDelta(t, 8*0.031611+32*0.004237, *cost.PricePerUnit, 1e-9)
+
+	cost, err = client.GetMachineTypeCost(context.Background(), "n2-custom-4-16384", "us-central1", wrapper.UsageTypeOnDemand)
+	assert.NoError(t, err, "Expected custom machine types to be priced")
+	assert.InDelta(t, 4*0.033191+16*0.004449, *cost.PricePerUnit, 1e-9)
+
+	cost, err = client.GetMachineTypeCost(context.Background(), "custom-2-7680", "us-central1", wrapper.UsageTypeOnDemand)
+	assert.NoError(t, err, "Expected N1 custom machine types to be priced")
+	assert.InDelta(t, 2*0.033174+7.5*0.004446, *cost.PricePerUnit, 1e-9)
+
+	cost, err = client.GetMachineTypeCost(context.Background(), "f1-micro", "us-central1", wrapper.UsageTypeOnDemand)
+	assert.NoError(t, err, "Expected f1-micro to be priced per instance")
+	assert.InDelta(t, 0.0076, *cost.PricePerUnit, 1e-9)
+
+	_, err = client.GetMachineTypeCost(context.Background(), "n2-standard-8", "europe-west1", wrapper.UsageTypeOnDemand)
+	assert.EqualError(t, err, "no OnDemand core price for n2-standard-8 in europe-west1")
+
+	_, err = client.GetMachineTypeCost(context.Background(), "n2-custom-4-16384-ext", "us-central1", wrapper.UsageTypeOnDemand)
+	assert.EqualError(t, err, "unknown machine type: n2-custom-4-16384-ext")
+
+	_, err = client.GetMachineTypeCostForLabels(context.Background(), map[string]string{}, wrapper.UsageTypeOnDemand)
+	assert.EqualError(t, err, "missing label: node.kubernetes.io/instance-type")
+}
+
+func TestGetReservedComputeCosts(t *testing.T) {
+	client := newCatalogClient(t, []*cloudbilling.Sku{
+		newSku("1", "N2 Instance Core running in Americas", "OnDemand", "h", 0, 31611000, "us-central1"),
+		newSku("2", "N2 Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 4237000, "us-central1"),
+		newSku("3", "Commitment v1: N2 Cpu in Americas for 1 Year", "Commit1Yr", "h", 0, 19915000, "us-central1"),
+		newSku("4", "Commitment v1: N2 Ram in Americas for 1 Year", "Commit1Yr", "GiBy.h", 0, 2669000, "us-central1"),
+		newSku("5", "Commitment v1: N2 Cpu in Americas for 3 Years", "Commit3Yr", "h", 0, 14225000, "us-central1"),
+		newSku("6", "Commitment v1: N2 Ram in Americas for 3 Years", "Commit3Yr", "GiBy.h", 0, 1907000, "us-central1"),
+	})
+
+	catalog, err := wrapper.LoadGcpMachineTypeCatalog([]byte(`{"version": "test", "machineTypes": [
+		{"name": "n2-standard-4", "family": "n2", "vCpu": 4, "memoryGb": 16, "architecture": "x86_64"}
+	]}`))
+	assert.NoError(t, err, "Expected the machine type catalog to load")
+	client.MachineTypeCatalog = catalog
+
+	costs, err := client.GetReservedComputeCosts(context.Background())
+	assert.NoError(t, err, "Expected no error from GetReservedComputeCosts")
+	assert.Equal(t, 2, len(*costs), "Expected one cost per commitment term")
+
+	cost := (*costs)[0]
+	assert.Equal(t, attendant.ProviderNameGcp, cost.Provider)
+	assert.Equal(t, "n2-standard-4", cost.Identifier)
+	assert.Equal(t, "us-central1", cost.Location)
+	assert.Equal(t, 1, cost.TermYears)
+	assert.Equal(t, "USD", cost.Currency)
+	assert.Equal(t, 0.0, cost.UpfrontFee)
+	assert.InDelta(t, 4*0.019915+16*0.002669, cost.EffectiveHourlyRate, 1e-9)
+
+	assert.Equal(t, 3, (*costs)[1].TermYears)
+	assert.InDelta(t, 4*0.014225+16*0.001907, (*costs)[1].EffectiveHourlyRate, 1e-9)
+}
+
+func TestGetMachineTypeCostDownloadsCatalogOnce(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cloudbilling.ListSkusResponse{Skus: []*cloudbilling.Sku{
+			newSku("1", "N2 Instance Core running in Americas", "OnDemand", "h", 0, 31611000, "us-central1"),
+			newSku("2", "N2 Instance Ram running in Americas", "OnDemand", "GiBy.h", 0, 4237000, "us-central1"),
+		}})
+	}))
+	defer server.Close()
+
+	service, err := cloudbilling.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	assert.NoError(t, err, "Expected the billing service to be created")
+
+	catalog, err := wrapper.NewGcpMachineTypeCatalog()
+	assert.NoError(t, err, "Expected the embedded machine type catalog to load")
+
+	client := &wrapper.GcpClient{BillingService: service, MachineTypeCatalog: catalog}
+
+	for _, machineType := range []string{"n2-standard-2", "n2-standard-4", "n2-highmem-8"} {
+		labels := map[string]string{
+			wrapper.LabelInstanceType:   machineType,
+			wrapper.LabelTopologyRegion: "us-central1",
+		}
+
+		_, err := client.GetMachineTypeCostForLabels(context.Background(), labels, wrapper.UsageTypeOnDemand)
+		assert.NoError(t, err, "Expected %s to be priced", machineType)
+	}
+
+	_, err = client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetDurableComputeConfigurations")
+
+	assert.Equal(t, int32(1), requests.Load(), "Expected the catalog to be downloaded once")
+}
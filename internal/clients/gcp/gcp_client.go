@@ -3,7 +3,9 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
+	"slices"
 
 	"cloud.google.com/go/bigquery"
 	attendant "github.com/be-heroes/ultron-attendant/pkg"
@@ -14,76 +16,373 @@ import (
 )
 
 type IGcpClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
+	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetSkuPrices(ctx context.Context) (*[]GcpSkuPrice, error)
+	GetComputeConfigurations(ctx context.Context, usageType GcpUsageType) (*[]ultron.ComputeConfiguration, error)
+	GetMachineTypeCost(ctx context.Context, machineTypeName string, region string, usageType GcpUsageType) (*ultron.ComputeCost, error)
+	GetMachineTypeCostForLabels(ctx context.Context, labels map[string]string, usageType GcpUsageType) (*ultron.ComputeCost, error)
 	GetComputeCost(ctx context.Context, projectId string) (*[]ultron.ComputeCost, error)
+	GetBillingRows(ctx context.Context, projectId string) (*[]GcpBillingRow, error)
+	GetEffectiveComputeCosts(ctx context.Context) (*[]attendant.EffectiveComputeCost, error)
+	GetReservedComputeCosts(ctx context.Context) (*[]attendant.ReservedComputeCost, error)
 }
 
-// TODO: Refactor client to return compute configs, as well as compute costs and adhere to the same interface as the emma & wisp clients
 type GcpClient struct {
-	credentials string
-	billingSvc  *cloudbilling.APIService
-	bqClient    *bigquery.Client
+	credentials         string
+	BillingService      *cloudbilling.APIService
+	BillingTable        *GcpBillingTable
+	QueryRunner         IBillingQueryRunner
+	MachineTypeCatalog  *GcpMachineTypeCatalog
+	Regions             []string
+	CurrencyCode        string
+	ProjectId           string
+	BillingLookbackDays int
+	priceTables         attendant.FetchCache[*GcpPriceTable]
 }
 
-func NewGcpClient() (*GcpClient, error) {
-	credentials := os.Getenv(attendant.EnvGoogleCredentials)
-	if credentials == "" {
-		return nil, fmt.Errorf("%s environment variable is not set", attendant.EnvGoogleCredentials)
+// NewGcpClient uses the credentials file from the config when one is set, and application default
+// credentials otherwise. The billing export is optional, since list prices come from the Cloud Billing
+// Catalog, but when any of its identifiers is set all of them must be valid.
+func NewGcpClient(config *attendant.Config) (*GcpClient, error) {
+	var options []option.ClientOption
+
+	if config.GcpCredentialsFile != "" {
+		if _, err := os.Stat(config.GcpCredentialsFile); err != nil {
+			return nil, fmt.Errorf("failed to read GCP credentials file: %v", err)
+		}
+
+		options = append(options, option.WithCredentialsFile(config.GcpCredentialsFile))
 	}
 
 	ctx := context.Background()
 
-	billingService, err := cloudbilling.NewService(ctx, option.WithCredentialsFile(credentials))
+	billingService, err := cloudbilling.NewService(ctx, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create billing service: %v", err)
 	}
 
-	bqClient, err := bigquery.NewClient(ctx, "your-project-id", option.WithCredentialsFile(credentials))
+	machineTypeCatalog, err := NewGcpMachineTypeCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &GcpClient{
+		credentials:        config.GcpCredentialsFile,
+		BillingService:     billingService,
+		MachineTypeCatalog: machineTypeCatalog,
+		ProjectId:          config.GcpProjectId,
+	}
+
+	if config.GcpBillingProjectId == "" && config.GcpBillingDatasetId == "" && config.GcpBillingTableId == "" {
+		return client, nil
+	}
+
+	billingTable, err := NewGcpBillingTable(config)
+	if err != nil {
+		return nil, err
+	}
+
+	bqClient, err := bigquery.NewClient(ctx, billingTable.ProjectId, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create BigQuery client: %v", err)
 	}
 
-	return &GcpClient{
-		credentials: credentials,
-		billingSvc:  billingService,
-		bqClient:    bqClient,
-	}, nil
+	client.BillingTable = billingTable
+	client.QueryRunner = NewBigQueryRunner(bqClient)
+
+	return client, nil
+}
+
+func (g *GcpClient) GetName() string {
+	return attendant.ProviderNameGcp
+}
+
+func (g *GcpClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+}
+
+func (g *GcpClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	durableConfigs, err := g.GetDurableComputeConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralConfigs, err := g.GetEphemeralComputeConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := append(*durableConfigs, *ephemeralConfigs...)
+
+	return &configs, nil
+}
+
+func (g *GcpClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return g.GetComputeConfigurations(ctx, UsageTypeOnDemand)
+}
+
+func (g *GcpClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return g.GetComputeConfigurations(ctx, UsageTypePreemptible)
+}
+
+// GetSkuPrices pages through the Compute Engine SKUs in the Cloud Billing Catalog and returns the core and RAM
+// prices of predefined and custom machine families, limited to Regions when any are set.
+func (g *GcpClient) GetSkuPrices(ctx context.Context) (*[]GcpSkuPrice, error) {
+	if g.BillingService == nil {
+		return nil, fmt.Errorf("GCP billing service is not configured")
+	}
+
+	call := g.BillingService.Services.Skus.List(ComputeEngineServiceName)
+	if g.CurrencyCode != "" {
+		call = call.CurrencyCode(g.CurrencyCode)
+	}
+
+	var prices []GcpSkuPrice
+
+	err := call.Pages(ctx, func(resp *cloudbilling.ListSkusResponse) error {
+		for _, sku := range resp.Skus {
+			skuPrices, ok := parseSku(sku)
+			if !ok {
+				continue
+			}
+
+			for _, price := range skuPrices {
+				if len(g.Regions) == 0 || slices.Contains(g.Regions, price.Region) {
+					prices = append(prices, price)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Compute Engine SKUs: %v", err)
+	}
+
+	return &prices, nil
+}
+
+// getPriceTable indexes the SKU prices of the catalog, which is downloaded at most once per refresh cycle so that
+// pricing many machine types or nodes does not page through the catalog for each of them.
+func (g *GcpClient) getPriceTable(ctx context.Context) (*GcpPriceTable, error) {
+	return g.priceTables.Get(ctx, ComputeEngineServiceName, attendant.DefaultFetchCacheTtl, func(ctx context.Context) (*GcpPriceTable, error) {
+		prices, err := g.GetSkuPrices(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewGcpPriceTable(*prices), nil
+	})
+}
+
+// GetComputeConfigurations prices every predefined machine type in the catalog in every region where its
+// family has prices of the given usage type. On-demand configurations are durable and preemptible (Spot)
+// configurations are ephemeral.
+func (g *GcpClient) GetComputeConfigurations(ctx context.Context, usageType GcpUsageType) (*[]ultron.ComputeConfiguration, error) {
+	if g.MachineTypeCatalog == nil {
+		return nil, fmt.Errorf("machine type catalog is not configured")
+	}
+
+	priceTable, err := g.getPriceTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	computeType := ultron.ComputeTypeDurable
+	if usageType == UsageTypePreemptible {
+		computeType = ultron.ComputeTypeEphemeral
+	}
+
+	var configs []ultron.ComputeConfiguration
+
+	for _, machineType := range g.MachineTypeCatalog.MachineTypes {
+		for _, region := range priceTable.GetRegions(machineType.Family, usageType) {
+			cost, err := priceTable.GetMachineTypeCost(&machineType, region, usageType)
+			if err != nil {
+				continue
+			}
+
+			configs = append(configs, mapConfiguration(&machineType, region, cost, computeType))
+		}
+	}
+
+	return &configs, nil
+}
+
+// GetReservedComputeCosts prices every predefined machine type in the catalog under 1 and 3 year committed use
+// discounts in every region where its family has commitment prices. Commitments are billed monthly without an
+// upfront fee, so the effective hourly rate is the committed hourly rate.
+func (g *GcpClient) GetReservedComputeCosts(ctx context.Context) (*[]attendant.ReservedComputeCost, error) {
+	if g.MachineTypeCatalog == nil {
+		return nil, fmt.Errorf("machine type catalog is not configured")
+	}
+
+	priceTable, err := g.getPriceTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := []attendant.ReservedComputeCost{}
+
+	for _, commitment := range []struct {
+		usageType GcpUsageType
+		termYears int
+	}{{UsageTypeCommit1Yr, 1}, {UsageTypeCommit3Yr, 3}} {
+		for _, machineType := range g.MachineTypeCatalog.MachineTypes {
+			for _, region := range priceTable.GetRegions(machineType.Family, commitment.usageType) {
+				cost, err := priceTable.GetMachineTypeCost(&machineType, region, commitment.usageType)
+				if err != nil {
+					continue
+				}
+
+				costs = append(costs, attendant.ReservedComputeCost{
+					Provider:            attendant.ProviderNameGcp,
+					Identifier:          machineType.Name,
+					Location:            region,
+					TermYears:           commitment.termYears,
+					Currency:            *cost.Currency,
+					HourlyRate:          *cost.PricePerUnit,
+					EffectiveHourlyRate: *cost.PricePerUnit,
+				})
+			}
+		}
+	}
+
+	return &costs, nil
+}
+
+// GetMachineTypeCost prices a predefined or custom machine type, e.g. e2-standard-4 or n2-custom-4-16384,
+// in a region.
+func (g *GcpClient) GetMachineTypeCost(ctx context.Context, machineTypeName string, region string, usageType GcpUsageType) (*ultron.ComputeCost, error) {
+	if g.MachineTypeCatalog == nil {
+		return nil, fmt.Errorf("machine type catalog is not configured")
+	}
+
+	machineType, ok := g.MachineTypeCatalog.GetMachineType(machineTypeName)
+	if !ok {
+		return nil, fmt.Errorf("unknown machine type: %s", machineTypeName)
+	}
+
+	priceTable, err := g.getPriceTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return priceTable.GetMachineTypeCost(machineType, region, usageType)
+}
+
+// GetMachineTypeCostForLabels prices a node from its instance type and region labels.
+func (g *GcpClient) GetMachineTypeCostForLabels(ctx context.Context, labels map[string]string, usageType GcpUsageType) (*ultron.ComputeCost, error) {
+	machineTypeName, ok := labels[LabelInstanceType]
+	if !ok {
+		return nil, fmt.Errorf("missing label: %s", LabelInstanceType)
+	}
+
+	region, ok := labels[LabelTopologyRegion]
+	if !ok {
+		return nil, fmt.Errorf("missing label: %s", LabelTopologyRegion)
+	}
+
+	return g.GetMachineTypeCost(ctx, machineTypeName, region, usageType)
 }
 
 func (g *GcpClient) GetComputeCost(ctx context.Context, projectId string) (*[]ultron.ComputeCost, error) {
+	rows, err := g.GetBillingRows(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var computeCosts []ultron.ComputeCost
+
+	for _, row := range *rows {
+		computeCost := ultron.ComputeCost{
+			Unit:         &row.UsageUnit,
+			Currency:     &row.Currency,
+			PricePerUnit: func(f float64) *float64 { v := float64(f); return &v }(row.Cost),
+		}
+
+		computeCosts = append(computeCosts, computeCost)
+	}
+
+	return &computeCosts, nil
+}
+
+func mapConfiguration(machineType *GcpMachineType, region string, cost *ultron.ComputeCost, computeType ultron.ComputeType) ultron.ComputeConfiguration {
+	provider := attendant.ProviderNameGcp
+	name := machineType.Name
+	location := region
+	architecture := machineType.Architecture
+	vCpu := machineType.VCpu
+	ramGb := int64(math.Round(machineType.MemoryGb))
+
+	return ultron.ComputeConfiguration{
+		Identifier:  &name,
+		Provider:    &provider,
+		Location:    &location,
+		VCpuType:    &architecture,
+		VCpu:        &vCpu,
+		RamGb:       &ramGb,
+		ComputeType: computeType,
+		Cost:        cost,
+	}
+}
+
+func (g *GcpClient) GetBillingRows(ctx context.Context, projectId string) (*[]GcpBillingRow, error) {
+	if g.BillingTable == nil || g.QueryRunner == nil {
+		return nil, fmt.Errorf("GCP billing export is not configured")
+	}
+
+	lookbackDays := g.BillingLookbackDays
+	if lookbackDays <= 0 {
+		lookbackDays = DefaultBillingLookbackDays
+	}
+
+	resourceName := "CAST(NULL AS STRING)"
+	if g.BillingTable.IsDetailed() {
+		resourceName = "resource.name"
+	}
+
 	query := `
 		SELECT 
 			service.description AS service_name,
 			sku.description AS sku_name,
+			` + resourceName + ` AS resource_name,
+			location.region AS region,
+			(SELECT value FROM UNNEST(system_labels) WHERE key = 'compute.googleapis.com/machine_spec') AS machine_type,
 			usage_start_time,
 			usage_end_time,
 			usage.amount AS usage_amount,
 			usage.unit AS usage_unit,
-			cost AS cost_in_usd,
+			cost,
+			IFNULL((SELECT SUM(credit.amount) FROM UNNEST(credits) AS credit), 0) AS credits,
 			currency
 		FROM 
-			` + "`your-project-id.gcp_billing_dataset.gcp_billing_export`" + `
+			` + g.BillingTable.String() + `
 		WHERE 
 			service.description = 'Compute Engine'
 			AND project.id = @projectId
+			AND usage_start_time >= TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL @lookbackDays DAY)
 		ORDER BY
 			usage_start_time DESC
 	`
 
-	q := g.bqClient.Query(query)
-	q.Parameters = []bigquery.QueryParameter{
+	it, err := g.QueryRunner.Query(ctx, query, []bigquery.QueryParameter{
 		{Name: "projectId", Value: projectId},
-	}
-
-	it, err := q.Read(ctx)
+		{Name: "lookbackDays", Value: lookbackDays},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to run BigQuery query: %v", err)
 	}
 
-	var computeCosts []ultron.ComputeCost
+	var rows []GcpBillingRow
 
 	for {
-		var values []bigquery.Value
-		err := it.Next(&values)
+		var row GcpBillingRow
+		err := it.Next(&row)
 		if err == iterator.Done {
 			break
 		}
@@ -91,23 +390,26 @@ func (g *GcpClient) GetComputeCost(ctx context.Context, projectId string) (*[]ul
 			return nil, fmt.Errorf("failed to iterate through query results: %v", err)
 		}
 
-		// serviceName := values[0].(string)
-		// skuName := values[1].(string)
-		// usageStart := values[2].(string)
-		// usageEnd := values[3].(string)
-		// usageAmount := values[4].(float64)
-		usageUnit := values[5].(string)
-		cost := values[6].(float64)
-		currency := values[7].(string)
+		rows = append(rows, row)
+	}
 
-		computeCost := ultron.ComputeCost{
-			Unit:         &usageUnit,
-			Currency:     &currency,
-			PricePerUnit: func(f float64) *float64 { v := float64(f); return &v }(cost),
-		}
+	return &rows, nil
+}
 
-		computeCosts = append(computeCosts, computeCost)
+// GetEffectiveComputeCosts turns the billing export of the configured project into the effective hourly
+// cost of each machine type per region and day, including discounts and credits. Without a project or billing
+// export there is no spend to report, which is a valid setup since list prices do not need the export.
+func (g *GcpClient) GetEffectiveComputeCosts(ctx context.Context) (*[]attendant.EffectiveComputeCost, error) {
+	if g.ProjectId == "" || g.BillingTable == nil || g.QueryRunner == nil {
+		return &[]attendant.EffectiveComputeCost{}, nil
 	}
 
-	return &computeCosts, nil
+	rows, err := g.GetBillingRows(ctx, g.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := aggregateBillingRows(*rows, g.MachineTypeCatalog)
+
+	return &costs, nil
 }
@@ -0,0 +1,183 @@
+package gcp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/gcp"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/iterator"
+)
+
+type fakeQueryRunner struct {
+	rows       []wrapper.GcpBillingRow
+	err        error
+	query      string
+	parameters []bigquery.QueryParameter
+}
+
+func (r *fakeQueryRunner) Query(ctx context.Context, query string, parameters []bigquery.QueryParameter) (wrapper.IBillingRowIterator, error) {
+	r.query = query
+	r.parameters = parameters
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &fakeRowIterator{rows: r.rows}, nil
+}
+
+type fakeRowIterator struct {
+	rows []wrapper.GcpBillingRow
+}
+
+func (it *fakeRowIterator) Next(dst interface{}) error {
+	if len(it.rows) == 0 {
+		return iterator.Done
+	}
+
+	*dst.(*wrapper.GcpBillingRow) = it.rows[0]
+	it.rows = it.rows[1:]
+
+	return nil
+}
+
+func TestNewGcpBillingTable(t *testing.T) {
+	config := &attendant.Config{
+		GcpBillingProjectId: "billing-project",
+		GcpBillingDatasetId: "billing_dataset",
+		GcpBillingTableId:   "gcp_billing_export_resource_v1_010101_ABCDEF_123456",
+	}
+
+	table, err := wrapper.NewGcpBillingTable(config)
+	assert.NoError(t, err, "Expected a valid billing table")
+	assert.True(t, table.IsDetailed(), "Expected a detailed export table")
+	assert.Equal(t, "`billing-project.billing_dataset.gcp_billing_export_resource_v1_010101_ABCDEF_123456`", table.String())
+
+	config.GcpBillingTableId = "gcp_billing_export_v1_010101_ABCDEF_123456"
+
+	table, err = wrapper.NewGcpBillingTable(config)
+	assert.NoError(t, err, "Expected a valid billing table")
+	assert.False(t, table.IsDetailed(), "Expected a standard export table")
+}
+
+func TestNewGcpBillingTableValidation(t *testing.T) {
+	cases := []attendant.Config{
+		{GcpBillingProjectId: "", GcpBillingDatasetId: "billing_dataset", GcpBillingTableId: "table"},
+		{GcpBillingProjectId: "Billing_Project", GcpBillingDatasetId: "billing_dataset", GcpBillingTableId: "table"},
+		{GcpBillingProjectId: "billing-project", GcpBillingDatasetId: "billing-dataset", GcpBillingTableId: "table"},
+		{GcpBillingProjectId: "billing-project", GcpBillingDatasetId: "billing_dataset", GcpBillingTableId: "table` WHERE 1=1 --"},
+	}
+
+	for _, config := range cases {
+		_, err := wrapper.NewGcpBillingTable(&config)
+		assert.Error(t, err, "Expected validation to fail for %+v", config)
+	}
+}
+
+func TestGetComputeCost(t *testing.T) {
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	runner := &fakeQueryRunner{
+		rows: []wrapper.GcpBillingRow{
+			{
+				ServiceName:    "Compute Engine",
+				SkuName:        "N2 Instance Core running in Americas",
+				UsageStartTime: start,
+				UsageEndTime:   start.Add(time.Hour),
+				UsageAmount:    28800,
+				UsageUnit:      "seconds",
+				Cost:           0.2524,
+				Currency:       "USD",
+			},
+		},
+	}
+
+	client := &wrapper.GcpClient{
+		BillingTable: &wrapper.GcpBillingTable{ProjectId: "billing-project", DatasetId: "billing_dataset", TableId: "gcp_billing_export_v1_010101"},
+		QueryRunner:  runner,
+	}
+
+	computeCosts, err := client.GetComputeCost(context.Background(), "workload-project")
+	assert.NoError(t, err, "Expected no error from GetComputeCost")
+	assert.Equal(t, 1, len(*computeCosts))
+	assert.Equal(t, "seconds", *(*computeCosts)[0].Unit)
+	assert.Equal(t, "USD", *(*computeCosts)[0].Currency)
+	assert.Equal(t, 0.2524, *(*computeCosts)[0].PricePerUnit)
+
+	assert.True(t, strings.Contains(runner.query, "`billing-project.billing_dataset.gcp_billing_export_v1_010101`"), "Expected the configured table in the query")
+	assert.True(t, strings.Contains(runner.query, "CAST(NULL AS STRING) AS resource_name"), "Expected no resource column for a standard export")
+	assert.Equal(t, "workload-project", runner.parameters[0].Value)
+}
+
+func TestGetComputeCostQueryError(t *testing.T) {
+	client := &wrapper.GcpClient{
+		BillingTable: &wrapper.GcpBillingTable{ProjectId: "billing-project", DatasetId: "billing_dataset", TableId: "gcp_billing_export_v1_010101"},
+		QueryRunner:  &fakeQueryRunner{err: errors.New("BigQuery error")},
+	}
+
+	_, err := client.GetComputeCost(context.Background(), "workload-project")
+	assert.EqualError(t, err, "failed to run BigQuery query: BigQuery error")
+
+	_, err = (&wrapper.GcpClient{}).GetComputeCost(context.Background(), "workload-project")
+	assert.EqualError(t, err, "GCP billing export is not configured")
+}
+
+func TestGetEffectiveComputeCosts(t *testing.T) {
+	day := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	machineType := bigquery.NullString{StringVal: "e2-standard-4", Valid: true}
+	region := bigquery.NullString{StringVal: "us-central1", Valid: true}
+	runner := &fakeQueryRunner{
+		rows: []wrapper.GcpBillingRow{
+			{SkuName: "E2 Instance Core running in Americas", MachineType: machineType, Region: region, UsageStartTime: day.Add(2 * time.Hour), UsageAmount: 4 * 3600, UsageUnit: "seconds", Cost: 0.087244, Credits: -0.02, Currency: "USD"},
+			{SkuName: "E2 Instance Ram running in Americas", MachineType: machineType, Region: region, UsageStartTime: day.Add(2 * time.Hour), UsageAmount: 16 * 3600 * 1 << 30, UsageUnit: "byte-seconds", Cost: 0.046768, Credits: -0.01, Currency: "USD"},
+			{SkuName: "E2 Instance Core running in Americas", MachineType: machineType, Region: region, UsageStartTime: day.Add(3 * time.Hour), UsageAmount: 4 * 3600, UsageUnit: "seconds", Cost: 0.087244, Currency: "USD"},
+			{SkuName: "E2 Instance Ram running in Americas", MachineType: machineType, Region: region, UsageStartTime: day.Add(3 * time.Hour), UsageAmount: 16 * 3600 * 1 << 30, UsageUnit: "byte-seconds", Cost: 0.046768, Currency: "USD"},
+			{SkuName: "E2 Instance Core running in Americas", MachineType: machineType, Region: region, UsageStartTime: day.Add(26 * time.Hour), UsageAmount: 4 * 3600, UsageUnit: "seconds", Cost: 0.087244, Currency: "USD"},
+			{SkuName: "Balanced PD Capacity", Region: region, UsageStartTime: day, UsageAmount: 100, UsageUnit: "byte-seconds", Cost: 0.5, Currency: "USD"},
+		},
+	}
+
+	catalog, err := wrapper.NewGcpMachineTypeCatalog()
+	assert.NoError(t, err, "Expected the embedded machine type catalog to load")
+
+	client := &wrapper.GcpClient{
+		BillingTable:       &wrapper.GcpBillingTable{ProjectId: "billing-project", DatasetId: "billing_dataset", TableId: "gcp_billing_export_v1_010101"},
+		QueryRunner:        runner,
+		MachineTypeCatalog: catalog,
+		ProjectId:          "workload-project",
+	}
+
+	costs, err := client.GetEffectiveComputeCosts(context.Background())
+	assert.NoError(t, err, "Expected no error from GetEffectiveComputeCosts")
+	assert.Equal(t, 2, len(*costs), "Expected one entry per machine type, region and day")
+
+	cost := (*costs)[0]
+	assert.Equal(t, attendant.ProviderNameGcp, cost.Provider)
+	assert.Equal(t, "e2-standard-4", cost.Identifier)
+	assert.Equal(t, "us-central1", cost.Location)
+	assert.Equal(t, day, cost.Date)
+	assert.Equal(t, "USD", cost.Currency)
+	assert.InDelta(t, 2.0, cost.UsageHours, 1e-9)
+	assert.InDelta(t, -0.03, cost.Credits, 1e-9)
+	assert.InDelta(t, (2*0.134012-0.03)/2, cost.EffectiveHourlyRate, 1e-9)
+
+	assert.Equal(t, day.Add(24*time.Hour), (*costs)[1].Date)
+	assert.InDelta(t, 0.087244, (*costs)[1].EffectiveHourlyRate, 1e-9)
+
+	assert.True(t, strings.Contains(runner.query, "compute.googleapis.com/machine_spec"), "Expected the machine type to be read from system labels")
+	assert.Equal(t, "workload-project", runner.parameters[0].Value)
+	assert.Equal(t, wrapper.DefaultBillingLookbackDays, runner.parameters[1].Value)
+
+	costs, err = (&wrapper.GcpClient{}).GetEffectiveComputeCosts(context.Background())
+	assert.NoError(t, err, "Expected no error without a project and billing export")
+	assert.Empty(t, *costs)
+
+	costs, err = (&wrapper.GcpClient{ProjectId: "workload-project"}).GetEffectiveComputeCosts(context.Background())
+	assert.NoError(t, err, "Expected no error without a billing export")
+	assert.Empty(t, *costs)
+}
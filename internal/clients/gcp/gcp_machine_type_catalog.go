@@ -0,0 +1,137 @@
+package gcp
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	LabelInstanceType   = "node.kubernetes.io/instance-type"
+	LabelTopologyRegion = "topology.kubernetes.io/region"
+)
+
+//go:embed data/machine_types.json
+var embeddedMachineTypeCatalog []byte
+
+// customMachineTypePattern matches custom machine types such as n2-custom-4-16384, where the shape is encoded
+// as vCPUs and MiB of memory. N1 custom machine types carry no family prefix. Extended memory types (-ext) are
+// not matched since extended memory SKUs are not ingested.
+var customMachineTypePattern = regexp.MustCompile(`^(?:([a-z][a-z0-9]*)-)?custom-([0-9]+)-([0-9]+)$`)
+
+// GcpMachineType is a predefined or custom machine shape. Shared-core machine types report more vCPUs than
+// they are billed for, so BilledVCpu carries the fraction of a vCPU that is charged.
+type GcpMachineType struct {
+	Name         string  `json:"name"`
+	Family       string  `json:"family"`
+	VCpu         int64   `json:"vCpu"`
+	MemoryGb     float64 `json:"memoryGb"`
+	Architecture string  `json:"architecture"`
+	SharedCore   bool    `json:"sharedCore,omitempty"`
+	BilledVCpu   float64 `json:"billedVCpu,omitempty"`
+	Custom       bool    `json:"-"`
+}
+
+type GcpMachineTypeCatalog struct {
+	Version      string           `json:"version"`
+	MachineTypes []GcpMachineType `json:"machineTypes"`
+
+	byName map[string]GcpMachineType
+}
+
+func NewGcpMachineTypeCatalog() (*GcpMachineTypeCatalog, error) {
+	return LoadGcpMachineTypeCatalog(embeddedMachineTypeCatalog)
+}
+
+func LoadGcpMachineTypeCatalogFromFile(path string) (*GcpMachineTypeCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read machine type catalog: %v", err)
+	}
+
+	return LoadGcpMachineTypeCatalog(data)
+}
+
+func LoadGcpMachineTypeCatalog(data []byte) (*GcpMachineTypeCatalog, error) {
+	var catalog GcpMachineTypeCatalog
+
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to decode machine type catalog: %v", err)
+	}
+
+	catalog.byName = make(map[string]GcpMachineType)
+
+	for _, machineType := range catalog.MachineTypes {
+		if machineType.Name == "" || machineType.Family == "" || machineType.VCpu <= 0 || machineType.MemoryGb <= 0 {
+			return nil, fmt.Errorf("invalid machine type catalog entry: %+v", machineType)
+		}
+
+		if machineType.SharedCore && machineType.BilledVCpu <= 0 {
+			return nil, fmt.Errorf("shared-core machine type has no billed vCPU: %s", machineType.Name)
+		}
+
+		catalog.byName[strings.ToLower(machineType.Name)] = machineType
+	}
+
+	return &catalog, nil
+}
+
+// GetMachineType looks up a predefined machine type by name, and otherwise parses the name as a custom
+// machine type.
+func (c *GcpMachineTypeCatalog) GetMachineType(name string) (*GcpMachineType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if machineType, ok := c.byName[name]; ok {
+		return &machineType, true
+	}
+
+	return parseCustomMachineType(name)
+}
+
+func (m *GcpMachineType) GetBilledVCpu() float64 {
+	if m.SharedCore {
+		return m.BilledVCpu
+	}
+
+	return float64(m.VCpu)
+}
+
+func parseCustomMachineType(name string) (*GcpMachineType, bool) {
+	match := customMachineTypePattern.FindStringSubmatch(name)
+	if match == nil {
+		return nil, false
+	}
+
+	family := match[1]
+	if family == "" {
+		family = "n1"
+	}
+
+	vCpu, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil || vCpu <= 0 {
+		return nil, false
+	}
+
+	memoryMb, err := strconv.ParseInt(match[3], 10, 64)
+	if err != nil || memoryMb <= 0 {
+		return nil, false
+	}
+
+	architecture := "x86_64"
+	if family == "t2a" {
+		architecture = "arm64"
+	}
+
+	return &GcpMachineType{
+		Name:         name,
+		Family:       family,
+		VCpu:         vCpu,
+		MemoryGb:     float64(memoryMb) / 1024,
+		Architecture: architecture,
+		Custom:       true,
+	}, true
+}
@@ -0,0 +1,63 @@
+package gcp_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/gcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGcpMachineTypeCatalog(t *testing.T) {
+	catalog, err := wrapper.NewGcpMachineTypeCatalog()
+	assert.NoError(t, err, "Expected embedded catalog to load")
+	assert.NotEmpty(t, catalog.Version, "Expected catalog version to be set")
+
+	machineType, ok := catalog.GetMachineType("E2-Standard-4")
+	assert.True(t, ok, "Expected e2-standard-4 to be present")
+	assert.Equal(t, "e2", machineType.Family)
+	assert.Equal(t, int64(4), machineType.VCpu)
+	assert.Equal(t, 16.0, machineType.MemoryGb)
+	assert.Equal(t, 4.0, machineType.GetBilledVCpu())
+
+	machineType, ok = catalog.GetMachineType("e2-micro")
+	assert.True(t, ok, "Expected e2-micro to be present")
+	assert.True(t, machineType.SharedCore)
+	assert.Equal(t, 0.25, machineType.GetBilledVCpu())
+
+	machineType, ok = catalog.GetMachineType("n2d-custom-6-24576")
+	assert.True(t, ok, "Expected custom machine types to be parsed")
+	assert.True(t, machineType.Custom)
+	assert.Equal(t, "n2d", machineType.Family)
+	assert.Equal(t, int64(6), machineType.VCpu)
+	assert.Equal(t, 24.0, machineType.MemoryGb)
+
+	machineType, ok = catalog.GetMachineType("custom-1-3840")
+	assert.True(t, ok, "Expected N1 custom machine types to be parsed")
+	assert.Equal(t, "n1", machineType.Family)
+
+	_, ok = catalog.GetMachineType("n2-unknown-4")
+	assert.False(t, ok, "Expected unknown machine type to be absent")
+}
+
+func TestLoadGcpMachineTypeCatalogFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "machine_types.json")
+	data := `{"version": "local", "machineTypes": [{"name": "x4-standard-2", "family": "x4", "vCpu": 2, "memoryGb": 8, "architecture": "arm64"}]}`
+
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0600))
+
+	catalog, err := wrapper.LoadGcpMachineTypeCatalogFromFile(path)
+	assert.NoError(t, err, "Expected local catalog to load")
+	assert.Equal(t, "local", catalog.Version)
+
+	machineType, ok := catalog.GetMachineType("x4-standard-2")
+	assert.True(t, ok)
+	assert.Equal(t, "arm64", machineType.Architecture)
+
+	_, err = wrapper.LoadGcpMachineTypeCatalogFromFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err, "Expected an error for a missing file")
+
+	_, err = wrapper.LoadGcpMachineTypeCatalog([]byte(`{"version": "1", "machineTypes": [{"name": "e2-micro", "family": "e2", "vCpu": 2, "memoryGb": 1, "sharedCore": true}]}`))
+	assert.Error(t, err, "Expected an error for a shared-core entry without a billed vCPU")
+}
@@ -1,12 +1,173 @@
 package jarvis
 
-// TODO: Finish Jarvis client
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+)
+
+const (
+	PredictionsPath = "/v1/predictions"
+	DefaultTimeout  = 30 * time.Second
+)
+
 type IJarvisClient interface {
+	GetPredictions(ctx context.Context, configurations []JarvisConfiguration) (map[string]JarvisPrediction, error)
+}
+
+// JarvisConfiguration identifies a compute configuration to Jarvis. Jarvis echoes it back with each
+// prediction, so predictions can be matched to the configurations they were requested for.
+type JarvisConfiguration struct {
+	InstanceType string `json:"instanceType,omitempty"`
+	Identifier   string `json:"identifier,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Location     string `json:"location,omitempty"`
+	ComputeType  string `json:"computeType,omitempty"`
+}
+
+// JarvisPrediction holds the predicted interruption rate (the probability that an instance is reclaimed within
+// the next hour) and latency rate of a configuration. Either is nil when Jarvis has no prediction for it.
+type JarvisPrediction struct {
+	Configuration    JarvisConfiguration `json:"configuration"`
+	InterruptionRate *float64            `json:"interruptionRate,omitempty"`
+	LatencyRate      *float64            `json:"latencyRate,omitempty"`
+}
+
+type JarvisPredictionRequest struct {
+	Configurations []JarvisConfiguration `json:"configurations"`
+}
+
+type JarvisPredictionResponse struct {
+	Predictions []JarvisPrediction `json:"predictions"`
 }
 
 type JarvisClient struct {
+	httpClient     *http.Client
+	predictionsUrl string
+	apiToken       string
 }
 
-func NewJarvisClient() (*JarvisClient, error) {
-	return &JarvisClient{}, nil
+func NewJarvisClient(config *attendant.Config) (*JarvisClient, error) {
+	if config.JarvisApiUrl == "" {
+		return nil, fmt.Errorf("jarvis API url is not configured")
+	}
+
+	apiUrl, err := url.Parse(config.JarvisApiUrl)
+	if err != nil || apiUrl.Scheme == "" || apiUrl.Host == "" {
+		return nil, fmt.Errorf("invalid jarvis API url: %s", config.JarvisApiUrl)
+	}
+
+	return &JarvisClient{
+		httpClient:     &http.Client{Timeout: DefaultTimeout},
+		predictionsUrl: strings.TrimSuffix(config.JarvisApiUrl, "/") + PredictionsPath,
+		apiToken:       config.JarvisApiToken,
+	}, nil
+}
+
+// NewJarvisConfiguration describes the node with the given instance type that was matched to configuration,
+// which may be nil when no configuration matched.
+func NewJarvisConfiguration(instanceType string, configuration *ultron.ComputeConfiguration) JarvisConfiguration {
+	result := JarvisConfiguration{InstanceType: instanceType}
+
+	if configuration == nil {
+		return result
+	}
+
+	if configuration.Identifier != nil {
+		result.Identifier = *configuration.Identifier
+	}
+
+	if configuration.Provider != nil {
+		result.Provider = *configuration.Provider
+	}
+
+	if configuration.Location != nil {
+		result.Location = *configuration.Location
+	}
+
+	result.ComputeType = string(configuration.ComputeType)
+
+	return result
+}
+
+func (c JarvisConfiguration) Key() string {
+	return strings.Join([]string{c.Provider, c.Location, c.InstanceType, c.Identifier, c.ComputeType}, "/")
+}
+
+// GetPredictions asks Jarvis for the predictions of all configurations in one request and returns them by
+// JarvisConfiguration.Key. Configurations Jarvis does not know are missing from the result.
+func (jc *JarvisClient) GetPredictions(ctx context.Context, configurations []JarvisConfiguration) (map[string]JarvisPrediction, error) {
+	predictions := make(map[string]JarvisPrediction)
+
+	request := JarvisPredictionRequest{Configurations: dedupeConfigurations(configurations)}
+	if len(request.Configurations) == 0 {
+		return predictions, nil
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode prediction request: %v", err)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, jc.predictionsUrl, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Accept", "application/json")
+
+	if jc.apiToken != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+jc.apiToken)
+	}
+
+	resp, err := jc.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch predictions: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		return nil, fmt.Errorf("failed to fetch predictions: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var response JarvisPredictionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode predictions: %v", err)
+	}
+
+	for _, prediction := range response.Predictions {
+		predictions[prediction.Configuration.Key()] = prediction
+	}
+
+	return predictions, nil
+}
+
+func dedupeConfigurations(configurations []JarvisConfiguration) []JarvisConfiguration {
+	seen := make(map[string]bool)
+
+	var results []JarvisConfiguration
+
+	for _, configuration := range configurations {
+		if seen[configuration.Key()] {
+			continue
+		}
+
+		seen[configuration.Key()] = true
+		results = append(results, configuration)
+	}
+
+	return results
 }
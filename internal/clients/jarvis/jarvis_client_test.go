@@ -0,0 +1,186 @@
+package jarvis_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	jarvis "github.com/be-heroes/ultron-attendant/internal/clients/jarvis"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+// jarvisServer answers prediction requests from its predictions, keyed by JarvisConfiguration.Key.
+type jarvisServer struct {
+	*httptest.Server
+	mutex       sync.Mutex
+	predictions map[string]jarvis.JarvisPrediction
+	requests    []jarvis.JarvisPredictionRequest
+	apiToken    string
+	statusCode  int
+}
+
+func newJarvisServer(t *testing.T, predictions ...jarvis.JarvisPrediction) *jarvisServer {
+	server := &jarvisServer{predictions: make(map[string]jarvis.JarvisPrediction)}
+
+	for _, prediction := range predictions {
+		server.predictions[prediction.Configuration.Key()] = prediction
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(jarvis.PredictionsPath, server.handlePredictions)
+
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Server.Close)
+
+	return server
+}
+
+func (s *jarvisServer) getRequests() []jarvis.JarvisPredictionRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]jarvis.JarvisPredictionRequest{}, s.requests...)
+}
+
+func (s *jarvisServer) handlePredictions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if s.apiToken != "" && r.Header.Get("Authorization") != "Bearer "+s.apiToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	var request jarvis.JarvisPredictionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, request)
+	s.mutex.Unlock()
+
+	if s.statusCode != 0 && s.statusCode != http.StatusOK {
+		http.Error(w, http.StatusText(s.statusCode), s.statusCode)
+
+		return
+	}
+
+	response := jarvis.JarvisPredictionResponse{Predictions: []jarvis.JarvisPrediction{}}
+
+	for _, configuration := range request.Configurations {
+		if prediction, ok := s.predictions[configuration.Key()]; ok {
+			prediction.Configuration = configuration
+			response.Predictions = append(response.Predictions, prediction)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func toFloat64Pointer(value float64) *float64 {
+	return &value
+}
+
+func toStringPointer(value string) *string {
+	return &value
+}
+
+func TestNewJarvisClient(t *testing.T) {
+	_, err := jarvis.NewJarvisClient(&attendant.Config{})
+	assert.EqualError(t, err, "jarvis API url is not configured")
+
+	_, err = jarvis.NewJarvisClient(&attendant.Config{JarvisApiUrl: "jarvis:8080"})
+	assert.EqualError(t, err, "invalid jarvis API url: jarvis:8080")
+}
+
+func TestNewJarvisConfiguration(t *testing.T) {
+	configuration := jarvis.NewJarvisConfiguration("m5.large", &ultron.ComputeConfiguration{
+		Identifier:  toStringPointer("101"),
+		Provider:    toStringPointer("aws"),
+		Location:    toStringPointer("eu-north-1"),
+		ComputeType: ultron.ComputeTypeEphemeral,
+	})
+
+	assert.Equal(t, jarvis.JarvisConfiguration{
+		InstanceType: "m5.large",
+		Identifier:   "101",
+		Provider:     "aws",
+		Location:     "eu-north-1",
+		ComputeType:  string(ultron.ComputeTypeEphemeral),
+	}, configuration)
+
+	assert.Equal(t, jarvis.JarvisConfiguration{InstanceType: "m5.large"}, jarvis.NewJarvisConfiguration("m5.large", nil))
+}
+
+func TestGetPredictions(t *testing.T) {
+	spot := jarvis.JarvisConfiguration{InstanceType: "m5.large", Provider: "aws", Location: "eu-north-1", ComputeType: string(ultron.ComputeTypeEphemeral)}
+	onDemand := jarvis.JarvisConfiguration{InstanceType: "m5.large", Provider: "aws", Location: "eu-north-1", ComputeType: string(ultron.ComputeTypeDurable)}
+	unknown := jarvis.JarvisConfiguration{InstanceType: "n2-standard-4", Provider: "gcp"}
+
+	server := newJarvisServer(t,
+		jarvis.JarvisPrediction{Configuration: spot, InterruptionRate: toFloat64Pointer(0.12), LatencyRate: toFloat64Pointer(0.3)},
+		jarvis.JarvisPrediction{Configuration: onDemand, LatencyRate: toFloat64Pointer(0.2)},
+	)
+	server.apiToken = "secret"
+
+	client, err := jarvis.NewJarvisClient(&attendant.Config{JarvisApiUrl: server.URL + "/", JarvisApiToken: "secret"})
+	assert.NoError(t, err)
+
+	predictions, err := client.GetPredictions(context.Background(), []jarvis.JarvisConfiguration{spot, onDemand, spot, unknown})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(predictions), "Expected no prediction for the unknown configuration")
+
+	assert.Equal(t, 0.12, *predictions[spot.Key()].InterruptionRate)
+	assert.Equal(t, 0.3, *predictions[spot.Key()].LatencyRate)
+	assert.Nil(t, predictions[onDemand.Key()].InterruptionRate)
+	assert.Equal(t, 0.2, *predictions[onDemand.Key()].LatencyRate)
+
+	requests := server.getRequests()
+	assert.Equal(t, 1, len(requests), "Expected all configurations in one request")
+	assert.Equal(t, 3, len(requests[0].Configurations), "Expected duplicate configurations to be sent once")
+}
+
+func TestGetPredictionsWithoutConfigurations(t *testing.T) {
+	server := newJarvisServer(t)
+
+	client, err := jarvis.NewJarvisClient(&attendant.Config{JarvisApiUrl: server.URL})
+	assert.NoError(t, err)
+
+	predictions, err := client.GetPredictions(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, predictions)
+	assert.Empty(t, server.getRequests())
+}
+
+func TestGetPredictionsFailures(t *testing.T) {
+	configurations := []jarvis.JarvisConfiguration{{InstanceType: "m5.large"}}
+
+	server := newJarvisServer(t)
+	server.apiToken = "secret"
+
+	client, err := jarvis.NewJarvisClient(&attendant.Config{JarvisApiUrl: server.URL, JarvisApiToken: "wrong"})
+	assert.NoError(t, err)
+
+	_, err = client.GetPredictions(context.Background(), configurations)
+	assert.EqualError(t, err, "failed to fetch predictions: status 401: unauthorized")
+
+	server.apiToken = ""
+	server.statusCode = http.StatusServiceUnavailable
+
+	_, err = client.GetPredictions(context.Background(), configurations)
+	assert.EqualError(t, err, "failed to fetch predictions: status 503: Service Unavailable")
+}
@@ -0,0 +1,194 @@
+package onprem
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	services "github.com/be-heroes/ultron/pkg/services"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const bytesPerGb = 1 << 30
+
+type IOnPremClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
+	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+}
+
+// OnPremClient turns the cluster's own bare-metal node pools into durable compute configurations, priced per
+// node-hour from the cost model. The cost model is read from CostModelFile on every call unless CostModel is set.
+type OnPremClient struct {
+	CostModelFile     string
+	CostModel         *OnPremCostModel
+	KubernetesService services.IKubernetesService
+}
+
+func NewOnPremClient(config *attendant.Config, kubernetesService services.IKubernetesService) *OnPremClient {
+	return &OnPremClient{
+		CostModelFile:     config.OnPremCostModelFile,
+		KubernetesService: kubernetesService,
+	}
+}
+
+func (oc *OnPremClient) GetName() string {
+	return attendant.ProviderNameOnPrem
+}
+
+func (oc *OnPremClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable}
+}
+
+func (oc *OnPremClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return oc.GetDurableComputeConfigurations(ctx)
+}
+
+// GetDurableComputeConfigurations emits one configuration per priced pool and disk and network type found on
+// its nodes. Its shape is the smallest allocatable shape among those nodes, rounded down, and its disk and
+// network types are copied from the node annotations, so that every node of the pool matches it.
+func (oc *OnPremClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	model, err := oc.getCostModel()
+	if err != nil {
+		return nil, err
+	}
+
+	if oc.KubernetesService == nil {
+		return nil, fmt.Errorf("kubernetes service is not configured")
+	}
+
+	nodes, err := oc.KubernetesService.GetNodes(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	shapes := make(map[onPremShapeKey]*onPremShape)
+
+	for _, node := range nodes {
+		pool, ok := model.GetPool(node.Labels[model.GetPoolLabel()])
+		if !ok {
+			continue
+		}
+
+		key := onPremShapeKey{
+			pool:        pool.Name,
+			diskType:    node.Annotations[ultron.AnnotationDiskType],
+			networkType: node.Annotations[ultron.AnnotationNetworkType],
+		}
+
+		shape := getNodeShape(&node)
+
+		if current, ok := shapes[key]; ok {
+			current.vCpu = min(current.vCpu, shape.vCpu)
+			current.ramGb = min(current.ramGb, shape.ramGb)
+			current.volumeGb = min(current.volumeGb, shape.volumeGb)
+		} else {
+			shapes[key] = &shape
+		}
+	}
+
+	keys := make([]onPremShapeKey, 0, len(shapes))
+	for key := range shapes {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.Join([]string{keys[i].pool, keys[i].diskType, keys[i].networkType}, "/") <
+			strings.Join([]string{keys[j].pool, keys[j].diskType, keys[j].networkType}, "/")
+	})
+
+	result := []ultron.ComputeConfiguration{}
+
+	for _, key := range keys {
+		pool, _ := model.GetPool(key.pool)
+
+		result = append(result, mapConfiguration(model, pool, key, shapes[key]))
+	}
+
+	return &result, nil
+}
+
+func (oc *OnPremClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return &[]ultron.ComputeConfiguration{}, nil
+}
+
+func (oc *OnPremClient) getCostModel() (*OnPremCostModel, error) {
+	if oc.CostModel != nil {
+		return oc.CostModel, nil
+	}
+
+	if oc.CostModelFile == "" {
+		return nil, fmt.Errorf("on-prem cost model is not configured")
+	}
+
+	return LoadOnPremCostModelFromFile(oc.CostModelFile)
+}
+
+type onPremShapeKey struct {
+	pool        string
+	diskType    string
+	networkType string
+}
+
+type onPremShape struct {
+	vCpu     int64
+	ramGb    int64
+	volumeGb int64
+}
+
+func getNodeShape(node *corev1.Node) onPremShape {
+	allocatable := node.Status.Allocatable
+
+	return onPremShape{
+		vCpu:     int64(math.Floor(allocatable.Cpu().AsApproximateFloat64())),
+		ramGb:    allocatable.Memory().Value() / bytesPerGb,
+		volumeGb: allocatable.StorageEphemeral().Value() / bytesPerGb,
+	}
+}
+
+func mapConfiguration(model *OnPremCostModel, pool *OnPremPoolCost, key onPremShapeKey, shape *onPremShape) ultron.ComputeConfiguration {
+	identifier := pool.Name
+	provider := attendant.ProviderNameOnPrem
+	location := model.GetLocation(pool)
+	vCpu := shape.vCpu
+	ramGb := shape.ramGb
+	volumeGb := shape.volumeGb
+	unit := attendant.CostUnitHours
+	currency := strings.ToUpper(model.Currency)
+	price := model.GetHourlyCost(pool)
+
+	config := ultron.ComputeConfiguration{
+		Identifier: &identifier,
+		Provider:   &provider,
+		Location:   &location,
+		VCpu:       &vCpu,
+		RamGb:      &ramGb,
+		VolumeGb:   &volumeGb,
+		Cost: &ultron.ComputeCost{
+			Unit:         &unit,
+			Currency:     &currency,
+			PricePerUnit: &price,
+		},
+		ComputeType: ultron.ComputeTypeDurable,
+	}
+
+	// Nodes without the disk or network type annotation leave the type unset rather than matching an empty one.
+	if key.diskType != "" {
+		volumeType := key.diskType
+
+		config.VolumeType = &volumeType
+	}
+
+	if key.networkType != "" {
+		config.CloudNetworkTypes = []string{key.networkType}
+	}
+
+	return config
+}
@@ -0,0 +1,109 @@
+package onprem_test
+
+import (
+	"context"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/onprem"
+	mocks "github.com/be-heroes/ultron-attendant/mocks"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(pool string, cpu string, memory string, storage string, diskType string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"example.com/pool": pool},
+			Annotations: map[string]string{ultron.AnnotationDiskType: diskType, ultron.AnnotationNetworkType: "isolated"},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse(cpu),
+				corev1.ResourceMemory:           resource.MustParse(memory),
+				corev1.ResourceEphemeralStorage: resource.MustParse(storage),
+			},
+		},
+	}
+}
+
+func newOnPremClient(t *testing.T, nodes ...corev1.Node) *wrapper.OnPremClient {
+	kubernetesService := mocks.NewIKubernetesService(t)
+	kubernetesService.On("GetNodes", mock.Anything, mock.Anything).Return(nodes, nil)
+
+	model, err := wrapper.LoadOnPremCostModel([]byte(costModel))
+	assert.NoError(t, err)
+
+	client := wrapper.NewOnPremClient(&attendant.Config{}, kubernetesService)
+	client.CostModel = model
+
+	return client
+}
+
+func TestGetDurableComputeConfigurations(t *testing.T) {
+	client := newOnPremClient(t,
+		newNode("bm-64c-512g", "63500m", "500Gi", "900Gi", "NVMe"),
+		newNode("bm-64c-512g", "63900m", "502Gi", "880Gi", "NVMe"),
+		newNode("bm-64c-512g", "63900m", "502Gi", "400Gi", "HDD"),
+		newNode("vm-4c-16g", "4", "16Gi", "100Gi", "SSD"),
+	)
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*configs), "Expected one configuration per disk type of the priced pool")
+
+	hdd := (*configs)[0]
+	assert.Equal(t, "HDD", *hdd.VolumeType)
+	assert.Equal(t, int64(400), *hdd.VolumeGb)
+
+	nvme := (*configs)[1]
+	assert.Equal(t, "bm-64c-512g", *nvme.Identifier)
+	assert.Equal(t, attendant.ProviderNameOnPrem, *nvme.Provider)
+	assert.Equal(t, "dc1", *nvme.Location)
+	assert.Equal(t, ultron.ComputeTypeDurable, nvme.ComputeType)
+	assert.Equal(t, []string{"isolated"}, nvme.CloudNetworkTypes)
+	assert.Equal(t, int64(63), *nvme.VCpu, "Expected the smallest allocatable shape, rounded down")
+	assert.Equal(t, int64(500), *nvme.RamGb)
+	assert.Equal(t, int64(880), *nvme.VolumeGb)
+	assert.Equal(t, "NVMe", *nvme.VolumeType)
+	assert.Equal(t, attendant.CostUnitHours, *nvme.Cost.Unit)
+	assert.Equal(t, "EUR", *nvme.Cost.Currency)
+	assert.InDelta(t, 0.85, *nvme.Cost.PricePerUnit, 1e-9)
+
+	ephemeral, err := client.GetEphemeralComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, *ephemeral)
+}
+
+func TestGetDurableComputeConfigurationsWithoutAnnotations(t *testing.T) {
+	node := newNode("bm-64c-512g", "63500m", "500Gi", "900Gi", "")
+	node.Annotations = nil
+
+	client := newOnPremClient(t, node)
+
+	configs, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*configs))
+
+	config := (*configs)[0]
+	assert.Equal(t, "bm-64c-512g", *config.Identifier)
+	assert.Nil(t, config.VolumeType, "Expected no volume type without a disk type annotation")
+	assert.Empty(t, config.CloudNetworkTypes, "Expected no network types without a network type annotation")
+	assert.Equal(t, int64(900), *config.VolumeGb)
+}
+
+func TestGetDurableComputeConfigurationsWithoutCostModel(t *testing.T) {
+	client := wrapper.NewOnPremClient(&attendant.Config{}, nil)
+
+	_, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.EqualError(t, err, "on-prem cost model is not configured")
+
+	client = wrapper.NewOnPremClient(&attendant.Config{OnPremCostModelFile: "/nonexistent/cost-model.yaml"}, nil)
+
+	_, err = client.GetDurableComputeConfigurations(context.Background())
+	assert.ErrorContains(t, err, "failed to read on-prem cost model")
+}
@@ -0,0 +1,181 @@
+package onprem
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DefaultPoolLabel = ultron.LabelInstanceType
+	DefaultLocation  = "onprem"
+	DefaultPue       = 1.0
+
+	HoursPerMonth = attendant.HoursPerYear / 12
+)
+
+// OnPremCostModel prices the bare-metal node pools of a cluster. The top-level values apply to every pool that
+// does not set its own.
+type OnPremCostModel struct {
+	Currency          string           `json:"currency"`
+	Location          string           `json:"location,omitempty"`
+	PoolLabel         string           `json:"poolLabel,omitempty"`
+	DepreciationYears float64          `json:"depreciationYears,omitempty"`
+	PowerPricePerKwh  float64          `json:"powerPricePerKwh,omitempty"`
+	Pue               float64          `json:"pue,omitempty"`
+	Pools             []OnPremPoolCost `json:"pools"`
+}
+
+// OnPremPoolCost holds the costs of one node of a pool: the hardware is written off in equal parts over the
+// depreciation period, the average power draw is scaled by the power usage effectiveness (PUE) of the site,
+// and colocation and maintenance are paid monthly.
+type OnPremPoolCost struct {
+	Name                string  `json:"name"`
+	Location            string  `json:"location,omitempty"`
+	CapexPerNode        float64 `json:"capexPerNode"`
+	DepreciationYears   float64 `json:"depreciationYears,omitempty"`
+	PowerWatts          float64 `json:"powerWatts,omitempty"`
+	PowerPricePerKwh    float64 `json:"powerPricePerKwh,omitempty"`
+	Pue                 float64 `json:"pue,omitempty"`
+	ColocationPerMonth  float64 `json:"colocationPerMonth,omitempty"`
+	MaintenancePerMonth float64 `json:"maintenancePerMonth,omitempty"`
+}
+
+func LoadOnPremCostModelFromFile(path string) (*OnPremCostModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read on-prem cost model: %v", err)
+	}
+
+	return LoadOnPremCostModel(data)
+}
+
+// LoadOnPremCostModel decodes a cost model from YAML or JSON, rejecting unknown fields, and validates it.
+func LoadOnPremCostModel(data []byte) (*OnPremCostModel, error) {
+	var model OnPremCostModel
+
+	if err := yaml.UnmarshalStrict(data, &model); err != nil {
+		return nil, fmt.Errorf("failed to decode on-prem cost model: %v", err)
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &model, nil
+}
+
+func (m *OnPremCostModel) Validate() error {
+	if strings.TrimSpace(m.Currency) == "" {
+		return fmt.Errorf("on-prem cost model has no currency")
+	}
+
+	if m.DepreciationYears < 0 || m.PowerPricePerKwh < 0 || m.Pue < 0 {
+		return fmt.Errorf("on-prem cost model has negative defaults")
+	}
+
+	seen := make(map[string]bool)
+
+	for _, pool := range m.Pools {
+		if pool.Name == "" {
+			return fmt.Errorf("on-prem pool is missing a name")
+		}
+
+		if seen[pool.Name] {
+			return fmt.Errorf("on-prem pool %s is defined twice", pool.Name)
+		}
+
+		seen[pool.Name] = true
+
+		if pool.CapexPerNode < 0 || pool.DepreciationYears < 0 || pool.PowerWatts < 0 || pool.PowerPricePerKwh < 0 ||
+			pool.Pue < 0 || pool.ColocationPerMonth < 0 || pool.MaintenancePerMonth < 0 {
+			return fmt.Errorf("on-prem pool %s has negative costs", pool.Name)
+		}
+
+		if pool.CapexPerNode > 0 && m.getDepreciationYears(&pool) == 0 {
+			return fmt.Errorf("on-prem pool %s has capex but no depreciation period", pool.Name)
+		}
+
+		if pool.PowerWatts > 0 && m.getPowerPricePerKwh(&pool) == 0 {
+			return fmt.Errorf("on-prem pool %s draws power but has no power price", pool.Name)
+		}
+	}
+
+	return nil
+}
+
+func (m *OnPremCostModel) GetPool(name string) (*OnPremPoolCost, bool) {
+	for i := range m.Pools {
+		if m.Pools[i].Name == name {
+			return &m.Pools[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (m *OnPremCostModel) GetPoolLabel() string {
+	if m.PoolLabel != "" {
+		return m.PoolLabel
+	}
+
+	return DefaultPoolLabel
+}
+
+func (m *OnPremCostModel) GetLocation(pool *OnPremPoolCost) string {
+	if pool.Location != "" {
+		return pool.Location
+	}
+
+	if m.Location != "" {
+		return m.Location
+	}
+
+	return DefaultLocation
+}
+
+// GetHourlyCost returns what one node of the pool costs per hour of its service life.
+func (m *OnPremCostModel) GetHourlyCost(pool *OnPremPoolCost) float64 {
+	var cost float64
+
+	if years := m.getDepreciationYears(pool); years > 0 {
+		cost += pool.CapexPerNode / (years * attendant.HoursPerYear)
+	}
+
+	cost += pool.PowerWatts / 1000 * m.getPue(pool) * m.getPowerPricePerKwh(pool)
+	cost += (pool.ColocationPerMonth + pool.MaintenancePerMonth) / HoursPerMonth
+
+	return cost
+}
+
+func (m *OnPremCostModel) getDepreciationYears(pool *OnPremPoolCost) float64 {
+	if pool.DepreciationYears > 0 {
+		return pool.DepreciationYears
+	}
+
+	return m.DepreciationYears
+}
+
+func (m *OnPremCostModel) getPowerPricePerKwh(pool *OnPremPoolCost) float64 {
+	if pool.PowerPricePerKwh > 0 {
+		return pool.PowerPricePerKwh
+	}
+
+	return m.PowerPricePerKwh
+}
+
+func (m *OnPremCostModel) getPue(pool *OnPremPoolCost) float64 {
+	if pool.Pue > 0 {
+		return pool.Pue
+	}
+
+	if m.Pue > 0 {
+		return m.Pue
+	}
+
+	return DefaultPue
+}
@@ -0,0 +1,70 @@
+package onprem_test
+
+import (
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/onprem"
+	"github.com/stretchr/testify/assert"
+)
+
+const costModel = `
+currency: eur
+location: dc1
+poolLabel: example.com/pool
+depreciationYears: 5
+powerPricePerKwh: 0.25
+pue: 1.5
+pools:
+  - name: bm-64c-512g
+    capexPerNode: 21900
+    powerWatts: 400
+    colocationPerMonth: 146
+  - name: bm-gpu
+    location: dc2
+    capexPerNode: 87600
+    depreciationYears: 4
+    pue: 1.2
+    maintenancePerMonth: 73
+`
+
+func TestGetHourlyCost(t *testing.T) {
+	model, err := wrapper.LoadOnPremCostModel([]byte(costModel))
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com/pool", model.GetPoolLabel())
+
+	pool, ok := model.GetPool("bm-64c-512g")
+	assert.True(t, ok)
+
+	// 21900 / (5 * 8760) + 0.4 kW * 1.5 * 0.25 + 146 / 730
+	assert.InDelta(t, 0.5+0.15+0.2, model.GetHourlyCost(pool), 1e-9)
+	assert.Equal(t, "dc1", model.GetLocation(pool))
+
+	pool, ok = model.GetPool("bm-gpu")
+	assert.True(t, ok)
+
+	// 87600 / (4 * 8760) + 73 / 730, the pool draws no power
+	assert.InDelta(t, 2.5+0.1, model.GetHourlyCost(pool), 1e-9)
+	assert.Equal(t, "dc2", model.GetLocation(pool))
+
+	_, ok = model.GetPool("vm-4c-16g")
+	assert.False(t, ok)
+}
+
+func TestLoadOnPremCostModelValidation(t *testing.T) {
+	cases := map[string]string{
+		"pools: []": "on-prem cost model has no currency",
+		"currency: EUR\npools: [{capexPerNode: 1}]":          "on-prem pool is missing a name",
+		"currency: EUR\npools: [{name: a}, {name: a}]":       "on-prem pool a is defined twice",
+		"currency: EUR\npools: [{name: a, pue: -1}]":         "on-prem pool a has negative costs",
+		"currency: EUR\npools: [{name: a, capexPerNode: 1}]": "on-prem pool a has capex but no depreciation period",
+		"currency: EUR\npools: [{name: a, powerWatts: 100}]": "on-prem pool a draws power but has no power price",
+	}
+
+	for data, expected := range cases {
+		_, err := wrapper.LoadOnPremCostModel([]byte(data))
+		assert.EqualError(t, err, expected)
+	}
+
+	_, err := wrapper.LoadOnPremCostModel([]byte("currency: EUR\npools: [{name: a, capex: 1}]"))
+	assert.ErrorContains(t, err, `unknown field "capex"`)
+}
@@ -0,0 +1,340 @@
+package static
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FormatJson = "json"
+	FormatYaml = "yaml"
+	FormatCsv  = "csv"
+
+	// CsvListSeparator separates the entries of list columns such as cloudNetworkTypes.
+	CsvListSeparator = ";"
+)
+
+// CsvColumns are the columns a CSV catalog may have, in the order used by the examples. The cost columns map
+// onto the cost object of the JSON and YAML formats.
+var CsvColumns = []string{
+	"identifier", "provider", "location", "dataCenter", "osType", "osVersion", "cloudNetworkTypes", "vCpuType",
+	"vCpu", "ramGb", "volumeGb", "volumeType", "computeType", "costUnit", "costCurrency", "costPricePerUnit",
+}
+
+// StaticCatalog is a list of compute configurations maintained by hand. In JSON and YAML files the
+// configurations use the field names of ultron.ComputeConfiguration.
+type StaticCatalog struct {
+	Version        string                        `json:"version,omitempty"`
+	Configurations []ultron.ComputeConfiguration `json:"configurations"`
+}
+
+func LoadStaticCatalogFromFile(path string) (*StaticCatalog, error) {
+	format, err := GetFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static catalog: %v", err)
+	}
+
+	catalog, err := LoadStaticCatalog(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return catalog, nil
+}
+
+// LoadStaticCatalog decodes a catalog in the given format, rejecting unknown fields, and validates it.
+func LoadStaticCatalog(data []byte, format string) (*StaticCatalog, error) {
+	var catalog StaticCatalog
+
+	switch format {
+	case FormatJson:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&catalog); err != nil {
+			return nil, fmt.Errorf("failed to decode static catalog: %v", err)
+		}
+	case FormatYaml:
+		if err := yaml.UnmarshalStrict(data, &catalog); err != nil {
+			return nil, fmt.Errorf("failed to decode static catalog: %v", err)
+		}
+	case FormatCsv:
+		configurations, err := parseCsv(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode static catalog: %v", err)
+		}
+
+		catalog.Configurations = configurations
+	default:
+		return nil, fmt.Errorf("unsupported static catalog format: %s", format)
+	}
+
+	if err := catalog.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &catalog, nil
+}
+
+func GetFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJson, nil
+	case ".yaml", ".yml":
+		return FormatYaml, nil
+	case ".csv":
+		return FormatCsv, nil
+	}
+
+	return "", fmt.Errorf("unsupported static catalog file: %s", path)
+}
+
+// Validate checks that every configuration can be matched and priced by ultron: it needs an identifier,
+// provider, location, compute type, vCPU count, memory size and a cost. Costs are normalized to the emma
+// unit names. The first problem found is returned with the position of the configuration.
+func (c *StaticCatalog) Validate() error {
+	seen := make(map[string]int)
+
+	for i := range c.Configurations {
+		configuration := &c.Configurations[i]
+
+		if err := validateConfiguration(configuration); err != nil {
+			return fmt.Errorf("configuration %d: %v", i+1, err)
+		}
+
+		key := GetKey(configuration)
+		if first, ok := seen[key]; ok {
+			return fmt.Errorf("configuration %d: duplicate of configuration %d", i+1, first)
+		}
+
+		seen[key] = i + 1
+	}
+
+	return nil
+}
+
+// GetKey identifies a configuration across catalog files.
+func GetKey(configuration *ultron.ComputeConfiguration) string {
+	return strings.Join([]string{
+		*configuration.Provider,
+		*configuration.Location,
+		getString(configuration.DataCenter),
+		*configuration.Identifier,
+		string(configuration.ComputeType),
+	}, "/")
+}
+
+func validateConfiguration(configuration *ultron.ComputeConfiguration) error {
+	required := []struct {
+		name  string
+		value *string
+	}{
+		{"identifier", configuration.Identifier},
+		{"provider", configuration.Provider},
+		{"location", configuration.Location},
+	}
+
+	for _, field := range required {
+		if field.value == nil || strings.TrimSpace(*field.value) == "" {
+			return fmt.Errorf("missing %s", field.name)
+		}
+	}
+
+	switch configuration.ComputeType {
+	case ultron.ComputeTypeDurable, ultron.ComputeTypeEphemeral:
+	case "":
+		return fmt.Errorf("missing computeType")
+	default:
+		return fmt.Errorf("invalid computeType: %s", configuration.ComputeType)
+	}
+
+	if configuration.VCpu == nil || *configuration.VCpu <= 0 {
+		return fmt.Errorf("vCpu must be positive")
+	}
+
+	if configuration.RamGb == nil || *configuration.RamGb <= 0 {
+		return fmt.Errorf("ramGb must be positive")
+	}
+
+	if configuration.VolumeGb != nil && *configuration.VolumeGb < 0 {
+		return fmt.Errorf("volumeGb must not be negative")
+	}
+
+	cost := configuration.Cost
+	if cost == nil {
+		return fmt.Errorf("missing cost")
+	}
+
+	if cost.PricePerUnit == nil || *cost.PricePerUnit < 0 {
+		return fmt.Errorf("cost.pricePerUnit must not be negative")
+	}
+
+	if cost.Unit == nil || strings.TrimSpace(*cost.Unit) == "" {
+		return fmt.Errorf("missing cost.unit")
+	}
+
+	if cost.Currency == nil || strings.TrimSpace(*cost.Currency) == "" {
+		return fmt.Errorf("missing cost.currency")
+	}
+
+	unit := attendant.NormalizeCostUnit(*cost.Unit)
+	currency := strings.ToUpper(strings.TrimSpace(*cost.Currency))
+
+	cost.Unit = &unit
+	cost.Currency = &currency
+
+	return nil
+}
+
+func parseCsv(data []byte) ([]ultron.ComputeConfiguration, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+
+	known := make(map[string]bool)
+	for _, column := range CsvColumns {
+		known[column] = true
+	}
+
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+
+		if !known[header[i]] {
+			return nil, fmt.Errorf("unknown column: %s", header[i])
+		}
+	}
+
+	var configurations []ultron.ComputeConfiguration
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		configuration, err := parseCsvRecord(header, record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		configurations = append(configurations, *configuration)
+	}
+
+	return configurations, nil
+}
+
+func parseCsvRecord(header []string, record []string) (*ultron.ComputeConfiguration, error) {
+	configuration := ultron.ComputeConfiguration{}
+	cost := ultron.ComputeCost{}
+
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		var err error
+
+		switch column {
+		case "identifier":
+			configuration.Identifier = &value
+		case "provider":
+			configuration.Provider = &value
+		case "location":
+			configuration.Location = &value
+		case "dataCenter":
+			configuration.DataCenter = &value
+		case "osType":
+			configuration.OsType = &value
+		case "osVersion":
+			configuration.OsVersion = &value
+		case "cloudNetworkTypes":
+			for _, networkType := range strings.Split(value, CsvListSeparator) {
+				if networkType = strings.TrimSpace(networkType); networkType != "" {
+					configuration.CloudNetworkTypes = append(configuration.CloudNetworkTypes, networkType)
+				}
+			}
+		case "vCpuType":
+			configuration.VCpuType = &value
+		case "vCpu":
+			configuration.VCpu, err = parseInt(value)
+		case "ramGb":
+			configuration.RamGb, err = parseInt(value)
+		case "volumeGb":
+			configuration.VolumeGb, err = parseInt(value)
+		case "volumeType":
+			configuration.VolumeType = &value
+		case "computeType":
+			configuration.ComputeType = ultron.ComputeType(value)
+		case "costUnit":
+			cost.Unit = &value
+		case "costCurrency":
+			cost.Currency = &value
+		case "costPricePerUnit":
+			cost.PricePerUnit, err = parseFloat(value)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", column, err)
+		}
+	}
+
+	if cost.Unit != nil || cost.Currency != nil || cost.PricePerUnit != nil {
+		configuration.Cost = &cost
+	}
+
+	return &configuration, nil
+}
+
+func parseInt(value string) (*int64, error) {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+func parseFloat(value string) (*float64, error) {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+func getString(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
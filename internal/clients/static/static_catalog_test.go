@@ -0,0 +1,88 @@
+package static_test
+
+import (
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/static"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlCatalog = `
+version: "1"
+configurations:
+  - identifier: bm-64c-512g
+    provider: onprem
+    location: dc1
+    cloudNetworkTypes: [isolated]
+    vCpu: 64
+    ramGb: 512
+    computeType: durable
+    cost:
+      unit: Hrs
+      currency: eur
+      pricePerUnit: 1.85
+`
+
+func TestLoadStaticCatalogFormats(t *testing.T) {
+	jsonCatalog := `{"version": "1", "configurations": [{"identifier": "bm-64c-512g", "provider": "onprem", "location": "dc1",
+		"cloudNetworkTypes": ["isolated"], "vCpu": 64, "ramGb": 512, "computeType": "durable",
+		"cost": {"unit": "Hrs", "currency": "eur", "pricePerUnit": 1.85}}]}`
+
+	csvCatalog := "# hand-maintained\n" +
+		"identifier,provider,location,cloudNetworkTypes,vCpu,ramGb,computeType,costUnit,costCurrency,costPricePerUnit\n" +
+		"bm-64c-512g,onprem,dc1,isolated,64,512,durable,Hrs,eur,1.85\n"
+
+	catalogs := map[string]string{
+		wrapper.FormatJson: jsonCatalog,
+		wrapper.FormatYaml: yamlCatalog,
+		wrapper.FormatCsv:  csvCatalog,
+	}
+
+	for format, data := range catalogs {
+		catalog, err := wrapper.LoadStaticCatalog([]byte(data), format)
+		assert.NoError(t, err, format)
+		assert.Equal(t, 1, len(catalog.Configurations), format)
+
+		configuration := catalog.Configurations[0]
+		assert.Equal(t, "bm-64c-512g", *configuration.Identifier, format)
+		assert.Equal(t, []string{"isolated"}, configuration.CloudNetworkTypes, format)
+		assert.Equal(t, int64(64), *configuration.VCpu, format)
+		assert.Equal(t, int64(512), *configuration.RamGb, format)
+		assert.Equal(t, ultron.ComputeTypeDurable, configuration.ComputeType, format)
+		assert.Equal(t, attendant.CostUnitHours, *configuration.Cost.Unit, "Expected the unit to be normalized in %s", format)
+		assert.Equal(t, "EUR", *configuration.Cost.Currency, format)
+		assert.Equal(t, 1.85, *configuration.Cost.PricePerUnit, format)
+	}
+}
+
+func TestLoadStaticCatalogValidation(t *testing.T) {
+	header := "identifier,provider,location,vCpu,ramGb,computeType,costUnit,costCurrency,costPricePerUnit\n"
+
+	cases := map[string]string{
+		header + ",onprem,dc1,64,512,durable,HOURS,EUR,1.85\n":      "configuration 1: missing identifier",
+		header + "bm,onprem,dc1,64,512,reserved,HOURS,EUR,1.85\n":   "configuration 1: invalid computeType: reserved",
+		header + "bm,onprem,dc1,0,512,durable,HOURS,EUR,1.85\n":     "configuration 1: vCpu must be positive",
+		header + "bm,onprem,dc1,64,512,durable,,,\n":                "configuration 1: missing cost",
+		header + "bm,onprem,dc1,64,512,durable,HOURS,,1.85\n":       "configuration 1: missing cost.currency",
+		header + "bm,onprem,dc1,sixty,512,durable,HOURS,EUR,1.85\n": "failed to decode static catalog: line 2: invalid vCpu: strconv.ParseInt: parsing \"sixty\": invalid syntax",
+		"identifier,gpus\nbm,8\n":                                   "failed to decode static catalog: unknown column: gpus",
+		header + "bm,onprem,dc1,64,512,durable,HOURS,EUR,1.85\n" +
+			"bm,onprem,dc1,64,512,durable,HOURS,EUR,1.95\n": "configuration 2: duplicate of configuration 1",
+	}
+
+	for data, expected := range cases {
+		_, err := wrapper.LoadStaticCatalog([]byte(data), wrapper.FormatCsv)
+		assert.EqualError(t, err, expected)
+	}
+
+	_, err := wrapper.LoadStaticCatalog([]byte(yamlCatalog+"    gpus: 8\n"), wrapper.FormatYaml)
+	assert.ErrorContains(t, err, `unknown field "gpus"`)
+
+	_, err = wrapper.LoadStaticCatalog([]byte(`{"configurations": [{"identifier": "bm", "gpus": 8}]}`), wrapper.FormatJson)
+	assert.EqualError(t, err, `failed to decode static catalog: json: unknown field "gpus"`)
+
+	_, err = wrapper.GetFormat("catalog.xml")
+	assert.EqualError(t, err, "unsupported static catalog file: catalog.xml")
+}
@@ -0,0 +1,221 @@
+package static
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+)
+
+const DefaultWatchInterval = 30 * time.Second
+
+type IStaticClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
+	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	Load() error
+	Watch(ctx context.Context, onError func(error))
+}
+
+// StaticClient serves compute configurations from catalog files for clusters that cannot reach any pricing
+// API. Paths may name files or directories; a directory contributes its .json, .yaml, .yml and .csv files.
+type StaticClient struct {
+	Paths          []string
+	WatchInterval  time.Duration
+	mutex          sync.RWMutex
+	configurations []ultron.ComputeConfiguration
+	loaded         bool
+	signature      string
+}
+
+func NewStaticClient(config *attendant.Config) *StaticClient {
+	return &StaticClient{
+		Paths:         config.StaticCatalogPaths,
+		WatchInterval: DefaultWatchInterval,
+	}
+}
+
+func (sc *StaticClient) GetName() string {
+	return attendant.ProviderNameStatic
+}
+
+func (sc *StaticClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+}
+
+func (sc *StaticClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return sc.getConfigurations("")
+}
+
+func (sc *StaticClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return sc.getConfigurations(ultron.ComputeTypeDurable)
+}
+
+func (sc *StaticClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return sc.getConfigurations(ultron.ComputeTypeEphemeral)
+}
+
+// Load reads and validates all catalog files. The configurations are only replaced when every file is valid,
+// so a half-written or broken file leaves the last good catalog in place.
+func (sc *StaticClient) Load() error {
+	files, signature, err := sc.getFiles()
+	if err != nil {
+		return err
+	}
+
+	var configurations []ultron.ComputeConfiguration
+
+	seen := make(map[string]string)
+
+	for _, file := range files {
+		catalog, err := LoadStaticCatalogFromFile(file)
+		if err != nil {
+			return err
+		}
+
+		for _, configuration := range catalog.Configurations {
+			key := GetKey(&configuration)
+			if first, ok := seen[key]; ok {
+				return fmt.Errorf("%s: configuration %s is already defined in %s", file, key, first)
+			}
+
+			seen[key] = file
+			configurations = append(configurations, configuration)
+		}
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	sc.configurations = configurations
+	sc.loaded = true
+	sc.signature = signature
+
+	return nil
+}
+
+// Watch reloads the catalog whenever a catalog file is added, removed or modified, until ctx is done. Files
+// are polled every WatchInterval. Failed reloads are passed to onError, once per change, and keep the last good
+// catalog.
+func (sc *StaticClient) Watch(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(sc.WatchInterval)
+	defer ticker.Stop()
+
+	var failedSignature string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, signature, err := sc.getFiles()
+			if err == nil && (signature == sc.getSignature() || signature == failedSignature) {
+				continue
+			}
+
+			if err == nil {
+				if err = sc.Load(); err != nil {
+					failedSignature = signature
+				}
+			}
+
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (sc *StaticClient) getConfigurations(computeType ultron.ComputeType) (*[]ultron.ComputeConfiguration, error) {
+	sc.mutex.RLock()
+	loaded := sc.loaded
+	sc.mutex.RUnlock()
+
+	if !loaded {
+		if err := sc.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	result := []ultron.ComputeConfiguration{}
+
+	for _, configuration := range sc.configurations {
+		if computeType == "" || configuration.ComputeType == computeType {
+			result = append(result, configuration)
+		}
+	}
+
+	return &result, nil
+}
+
+func (sc *StaticClient) getSignature() string {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	return sc.signature
+}
+
+// getFiles expands the configured paths into catalog files, together with a signature of their names, sizes
+// and modification times that changes whenever one of them does.
+func (sc *StaticClient) getFiles() ([]string, string, error) {
+	if len(sc.Paths) == 0 {
+		return nil, "", fmt.Errorf("no static catalog paths configured")
+	}
+
+	var files []string
+
+	for _, path := range sc.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read static catalog: %v", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read static catalog: %v", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			if _, err := GetFormat(entry.Name()); err == nil {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	sort.Strings(files)
+
+	var signature strings.Builder
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read static catalog: %v", err)
+		}
+
+		fmt.Fprintf(&signature, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return files, signature.String(), nil
+}
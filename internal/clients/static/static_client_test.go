@@ -0,0 +1,100 @@
+package static_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/static"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+const spotCatalog = `identifier,provider,location,vCpu,ramGb,computeType,costUnit,costCurrency,costPricePerUnit
+vm-4c-16g,onprem,dc1,4,16,ephemeral,HOURS,EUR,0.04
+`
+
+func writeFile(t *testing.T, path string, data string) {
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+}
+
+func TestStaticClientLoadsDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "durable.yaml"), yamlCatalog)
+	writeFile(t, filepath.Join(dir, "spot.csv"), spotCatalog)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a catalog")
+
+	client := wrapper.NewStaticClient(&attendant.Config{StaticCatalogPaths: []string{dir}})
+	assert.Equal(t, attendant.ProviderNameStatic, client.GetName())
+
+	durable, err := client.GetDurableComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*durable))
+	assert.Equal(t, "bm-64c-512g", *(*durable)[0].Identifier)
+
+	ephemeral, err := client.GetEphemeralComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*ephemeral))
+	assert.Equal(t, ultron.ComputeTypeEphemeral, (*ephemeral)[0].ComputeType)
+
+	all, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(*all))
+}
+
+func TestStaticClientRejectsDuplicatesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), yamlCatalog)
+	writeFile(t, filepath.Join(dir, "b.yml"), yamlCatalog)
+
+	client := wrapper.NewStaticClient(&attendant.Config{StaticCatalogPaths: []string{dir}})
+
+	err := client.Load()
+	assert.EqualError(t, err, filepath.Join(dir, "b.yml")+": configuration onprem/dc1//bm-64c-512g/durable is already defined in "+filepath.Join(dir, "a.yaml"))
+
+	_, err = wrapper.NewStaticClient(&attendant.Config{}).GetDurableComputeConfigurations(context.Background())
+	assert.EqualError(t, err, "no static catalog paths configured")
+}
+
+func TestStaticClientWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spot.csv")
+	writeFile(t, path, spotCatalog)
+
+	client := wrapper.NewStaticClient(&attendant.Config{StaticCatalogPaths: []string{path}})
+	client.WatchInterval = 10 * time.Millisecond
+	assert.NoError(t, client.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errors := make(chan error, 10)
+
+	go client.Watch(ctx, func(err error) {
+		errors <- err
+	})
+
+	writeFile(t, path, "identifier,provider\nbroken\n")
+
+	select {
+	case err := <-errors:
+		assert.ErrorContains(t, err, path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the broken catalog to be reported")
+	}
+
+	configs, err := client.GetEphemeralComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(*configs), "Expected the last valid catalog to be kept")
+
+	writeFile(t, path, spotCatalog+"vm-8c-32g,onprem,dc1,8,32,ephemeral,HOURS,EUR,0.08\n")
+
+	assert.Eventually(t, func() bool {
+		configs, err := client.GetEphemeralComputeConfigurations(context.Background())
+
+		return err == nil && len(*configs) == 2
+	}, 5*time.Second, 10*time.Millisecond, "Expected the changed catalog to be reloaded")
+}
@@ -2,55 +2,216 @@ package wisp
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
 	ultron "github.com/be-heroes/ultron/pkg"
+	services "github.com/be-heroes/ultron/pkg/services"
 	wisp "github.com/wispcompute/wisp-go-sdk"
 )
 
 type IWispClient interface {
+	GetName() string
+	GetCapabilities() []attendant.ProviderCapability
 	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
 	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
 	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetOffers(ctx context.Context) (*[]WispOffer, *[]WispOfferReport, error)
+	GetComputeAccelerators(ctx context.Context) (*[]attendant.ComputeAccelerators, error)
 }
 
 type WispClient struct {
-	client       *wisp.APIClient
-	clientId     string
-	clientSecret string
+	client            *wisp.APIClient
+	TokenSource       IWispTokenSource
+	KubernetesService services.IKubernetesService
+	OfferTtl          time.Duration
+	ReportHandler     func(report WispOfferReport)
+	offers            attendant.FetchCache[*wispOffers]
 }
 
-func NewWispClient(clientId string, clientSecret string) *WispClient {
+type wispOffers struct {
+	offers  []WispOffer
+	reports []WispOfferReport
+}
+
+// NewWispClient reads a rotated token from the token file when one is configured, and otherwise obtains
+// tokens from the client credentials. Offers are constrained to the demand found through kubernetesService.
+func NewWispClient(config *attendant.Config, kubernetesService services.IKubernetesService) *WispClient {
+	configuration := wisp.NewConfiguration()
+	configuration.Servers = wisp.ServerConfigurations{{URL: strings.TrimSuffix(config.WispApiUrl, "/")}}
+
+	var tokenSource IWispTokenSource
+	if config.WispTokenFile != "" {
+		tokenSource = NewWispFileTokenSource(config.WispTokenFile)
+	} else {
+		tokenSource = NewWispCredentialsTokenSource(http.DefaultClient, config.WispApiUrl, config.WispClientId, config.WispClientSecret)
+	}
+
 	return &WispClient{
-		client:       wisp.NewAPIClient(wisp.NewConfiguration()),
-		clientId:     clientId,
-		clientSecret: clientSecret,
+		client:            wisp.NewAPIClient(configuration),
+		TokenSource:       tokenSource,
+		KubernetesService: kubernetesService,
+		OfferTtl:          attendant.DefaultFetchCacheTtl,
 	}
 }
 
+func (wc *WispClient) GetName() string {
+	return attendant.ProviderNameWisp
+}
+
+func (wc *WispClient) GetCapabilities() []attendant.ProviderCapability {
+	return []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+}
+
 func (wc *WispClient) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
-	// TODO: We probably need some args to map to our ConstrainRequest
-	// TODO: Fetch and configure bearer token (initally it is handrolled via their portal, until we can setup a STS)
-	constrainRequest := wisp.ConstrainRequest{}
-	constrainResponse, _, err := wc.client.ConstraintsApi.ConstraintsCreate(ctx).ConstrainRequest(constrainRequest).Execute()
+	offers, _, err := wc.GetOffers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	results := []ultron.ComputeConfiguration{}
 
-	for _, choice := range constrainResponse.GetChoice() {
-		var computeType ultron.ComputeType
+	for _, offer := range *offers {
+		results = append(results, offer.Configuration)
+	}
+
+	return &results, nil
+}
 
-		if *choice.UseSpot.Get() {
-			computeType = ultron.ComputeTypeEphemeral
-		} else {
-			computeType = ultron.ComputeTypeDurable
+// GetComputeAccelerators lists the accelerators of every offer that has any, once per instance type, cloud and
+// region, since the on-demand and spot offers of an instance type carry the same accelerators.
+func (wc *WispClient) GetComputeAccelerators(ctx context.Context) (*[]attendant.ComputeAccelerators, error) {
+	offers, _, err := wc.GetOffers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []attendant.ComputeAccelerators{}
+	seen := make(map[string]bool)
+
+	for _, offer := range *offers {
+		if len(offer.Accelerators) == 0 || offer.InstanceType == "" {
+			continue
 		}
 
-		results = append(results, wc.mapConfiguration(&choice, computeType))
+		key := *offer.Configuration.Provider + "/" + *offer.Configuration.Location + "/" + offer.InstanceType
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		results = append(results, attendant.ComputeAccelerators{
+			Provider:     *offer.Configuration.Provider,
+			Identifier:   offer.InstanceType,
+			Location:     *offer.Configuration.Location,
+			Accelerators: offer.Accelerators,
+		})
+	}
+
+	return &results, nil
+}
+
+// GetOffers queries Wisp once per distinct node shape and pending pod request in the cluster and returns an
+// offer per region of every distinct cluster offer. Offers that fail validation are left out and described,
+// along with offers that had missing optional fields, in the reports. The offers are fetched at most once per
+// OfferTtl, so that the durable and ephemeral configurations of a refresh cycle come from one set of queries,
+// and every report is passed to ReportHandler once per fetch.
+func (wc *WispClient) GetOffers(ctx context.Context) (*[]WispOffer, *[]WispOfferReport, error) {
+	result, err := wc.offers.Get(ctx, "", wc.OfferTtl, wc.fetchOffers)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	return &result.offers, &result.reports, nil
+}
+
+func (wc *WispClient) fetchOffers(ctx context.Context) (*wispOffers, error) {
+	demands, err := wc.GetDemands(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &results, nil
+	var clusterOffers []wisp.ClusterOffer
+
+	seen := make(map[string]bool)
+
+	for _, demand := range demands {
+		constrainResponse, err := wc.createConstraints(ctx, demand.ToConstrainRequest())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, choice := range constrainResponse.GetChoice() {
+			key, err := json.Marshal(choice)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode cluster offer: %v", err)
+			}
+
+			if seen[string(key)] {
+				continue
+			}
+
+			seen[string(key)] = true
+			clusterOffers = append(clusterOffers, choice)
+		}
+	}
+
+	offers := []WispOffer{}
+	reports := []WispOfferReport{}
+
+	for i, clusterOffer := range clusterOffers {
+		mapped, report := mapOffers(i, &clusterOffer)
+
+		offers = append(offers, mapped...)
+
+		if len(report.Errors) > 0 || len(report.Warnings) > 0 {
+			reports = append(reports, report)
+
+			if wc.ReportHandler != nil {
+				wc.ReportHandler(report)
+			}
+		}
+	}
+
+	return &wispOffers{offers: offers, reports: reports}, nil
+}
+
+// createConstraints attaches a bearer token to the request. A rejected token is invalidated and the request
+// retried once with a fresh one, since a cached token may have been revoked before it expired.
+func (wc *WispClient) createConstraints(ctx context.Context, constrainRequest wisp.ConstrainRequest) (*wisp.ConstrainResponse, error) {
+	if wc.TokenSource == nil {
+		return nil, fmt.Errorf("wisp token source is not configured")
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := wc.TokenSource.GetToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		auth := context.WithValue(ctx, wisp.ContextAccessToken, token)
+		constrainResponse, resp, err := wc.client.ConstraintsApi.ConstraintsCreate(auth).ConstrainRequest(constrainRequest).Execute()
+
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			wc.TokenSource.Invalidate()
+
+			if attempt == 0 {
+				continue
+			}
+
+			return nil, &WispAuthError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to create constraints: %v", err)
+		}
+
+		return &constrainResponse, nil
+	}
 }
 
 func (wc *WispClient) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
@@ -88,34 +249,3 @@ func (wc *WispClient) GetEphemeralComputeConfigurations(ctx context.Context) (*[
 
 	return &ephemeralConfigurations, nil
 }
-
-func (ec *WispClient) mapConfiguration(clusterOffer *wisp.ClusterOffer, computeType ultron.ComputeType) ultron.ComputeConfiguration {
-	provider := clusterOffer.Cloud.Get()
-	location := "unknown"
-
-	strArray, ok := clusterOffer.Regions.([]string)
-	if ok {
-		location = strArray[0]
-	}
-
-	cpuCount := int64(*clusterOffer.Cpus.Get())
-	diskSize := int64(*clusterOffer.DiskSize.Get())
-	memorySize := int64(*clusterOffer.Memory.Get())
-	price := float64(*clusterOffer.Price.Get())
-	priceUnit := "HOURS"
-	priceCurrency := "USD"
-
-	return ultron.ComputeConfiguration{
-		Provider: provider,
-		Location: &location,
-		VCpu:     &cpuCount,
-		RamGb:    &memorySize,
-		VolumeGb: &diskSize,
-		Cost: &ultron.ComputeCost{
-			Unit:         &priceUnit,
-			Currency:     &priceCurrency,
-			PricePerUnit: &price,
-		},
-		ComputeType: computeType,
-	}
-}
@@ -0,0 +1,309 @@
+package wisp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/wisp"
+	"github.com/be-heroes/ultron-attendant/mocks"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	wisp "github.com/wispcompute/wisp-go-sdk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const constrainResponse = `{"choice": [
+	{"cloud": "aws", "instance_type": "m5.large", "regions": ["us-east-1"], "cpus": 2, "memory": 8, "disk_size": 100, "price": 0.096, "use_spot": false},
+	{"cloud": "gcp", "instance_type": "n2-standard-4", "region": "us-central1", "cpus": 4, "memory": 16, "disk_size": 100, "price": 0.051, "use_spot": true}
+]}`
+
+type wispServer struct {
+	*httptest.Server
+	tokenRequests int32
+	validToken    atomic.Value
+	authHeaders   []string
+	resources     []wisp.Resources
+	response      string
+}
+
+func newKubernetesService(t *testing.T, nodes []corev1.Node, pods []corev1.Pod) *mocks.IKubernetesService {
+	kubernetesService := mocks.NewIKubernetesService(t)
+	kubernetesService.On("GetNodes", mock.Anything, mock.Anything).Return(nodes, nil)
+	kubernetesService.On("GetPods", mock.Anything, mock.Anything).Return(pods, nil)
+
+	return kubernetesService
+}
+
+func newNode(cpu string, memory string, labels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func newPod(phase corev1.PodPhase, nodeName string, requests ...corev1.ResourceList) corev1.Pod {
+	pod := corev1.Pod{
+		Spec:   corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+
+	for _, request := range requests {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Resources: corev1.ResourceRequirements{Requests: request}})
+	}
+
+	return pod
+}
+
+func newWispServer(t *testing.T, expiresIn int) *wispServer {
+	server := &wispServer{response: constrainResponse}
+	server.validToken.Store("token-1")
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case wrapper.TokenPath:
+			assert.NoError(t, r.ParseForm())
+
+			if r.PostForm.Get("client_id") != "client-id" || r.PostForm.Get("client_secret") != "client-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error": "invalid_client"}`)
+
+				return
+			}
+
+			count := atomic.AddInt32(&server.tokenRequests, 1)
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": %d}`, count, expiresIn)
+		case "/api/constraints/":
+			server.authHeaders = append(server.authHeaders, r.Header.Get("Authorization"))
+
+			var constrainRequest wisp.ConstrainRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&constrainRequest))
+			server.resources = append(server.resources, constrainRequest.Resources)
+
+			validToken := server.validToken.Load().(string)
+
+			if validToken != "*" && r.Header.Get("Authorization") != "Bearer "+validToken {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, server.response)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGetAllComputeConfigurationsWithCredentials(t *testing.T) {
+	server := newWispServer(t, 3600)
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+	client.OfferTtl = 0
+
+	configs, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetAllComputeConfigurations")
+	assert.Equal(t, 2, len(*configs))
+	assert.Equal(t, ultron.ComputeTypeDurable, (*configs)[0].ComputeType)
+	assert.Equal(t, ultron.ComputeTypeEphemeral, (*configs)[1].ComputeType)
+
+	_, err = client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.tokenRequests), "Expected the token to be cached")
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1"}, server.authHeaders)
+}
+
+func TestGetAllComputeConfigurationsRefreshesRejectedToken(t *testing.T) {
+	server := newWispServer(t, 3600)
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+	client.OfferTtl = 0
+
+	_, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+
+	server.validToken.Store("token-2")
+
+	_, err = client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected a revoked token to be replaced")
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-2"}, server.authHeaders)
+
+	server.validToken.Store("never")
+
+	_, err = client.GetAllComputeConfigurations(context.Background())
+
+	var authErr *wrapper.WispAuthError
+	assert.True(t, errors.As(err, &authErr), "Expected a WispAuthError, got %v", err)
+	assert.Equal(t, http.StatusUnauthorized, authErr.StatusCode)
+}
+
+func TestGetAllComputeConfigurationsRefreshesExpiredToken(t *testing.T) {
+	server := newWispServer(t, 1)
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+	client.OfferTtl = 0
+	server.validToken.Store("*")
+
+	_, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+
+	_, err = client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.tokenRequests), "Expected an expired token to be fetched again")
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, server.authHeaders)
+}
+
+func TestGetAllComputeConfigurationsInvalidCredentials(t *testing.T) {
+	server := newWispServer(t, 3600)
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "wrong"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+
+	_, err := client.GetAllComputeConfigurations(context.Background())
+
+	var authErr *wrapper.WispAuthError
+	assert.True(t, errors.As(err, &authErr), "Expected a WispAuthError, got %v", err)
+	assert.Equal(t, http.StatusUnauthorized, authErr.StatusCode)
+	assert.Empty(t, server.authHeaders, "Expected no API call without a token")
+}
+
+func TestGetAllComputeConfigurationsWithTokenFile(t *testing.T) {
+	server := newWispServer(t, 3600)
+	server.validToken.Store("rotated-1")
+
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("rotated-1\n"), 0600))
+
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispTokenFile: path}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+	client.OfferTtl = 0
+
+	_, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected the mounted token to be used")
+
+	server.validToken.Store("rotated-2")
+	assert.NoError(t, os.WriteFile(path, []byte("rotated-2"), 0600))
+
+	_, err = client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected the rotated token to be picked up")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&server.tokenRequests))
+
+	assert.NoError(t, os.WriteFile(path, []byte(""), 0600))
+
+	_, err = client.GetAllComputeConfigurations(context.Background())
+
+	var authErr *wrapper.WispAuthError
+	assert.True(t, errors.As(err, &authErr), "Expected a WispAuthError for an empty token file, got %v", err)
+}
+
+func TestGetAllComputeConfigurationsFromClusterDemand(t *testing.T) {
+	server := newWispServer(t, 3600)
+	nodes := []corev1.Node{
+		newNode("4", "16Gi", nil),
+		newNode("4", "16Gi", nil),
+		newNode("8", "32Gi", map[string]string{wrapper.LabelGkeAccelerator: "nvidia-tesla-t4"}),
+	}
+	nodes[2].Status.Capacity[wrapper.ResourceNvidiaGpu] = resource.MustParse("1")
+	pods := []corev1.Pod{
+		newPod(corev1.PodPending, "", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("3Gi")}, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}),
+		newPod(corev1.PodPending, "node-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("16")}),
+		newPod(corev1.PodRunning, "node-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("32")}),
+	}
+
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, nodes, pods))
+
+	configs, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err, "Expected no error from GetAllComputeConfigurations")
+	assert.Equal(t, 2, len(*configs), "Expected offers returned for several demands to be merged")
+	assert.Equal(t, 3, len(server.resources), "Expected one request per distinct demand")
+
+	assert.Equal(t, int32(2), server.resources[0].GetCpus())
+	assert.Equal(t, int32(4), server.resources[0].GetMemory())
+	assert.Equal(t, int32(4), server.resources[1].GetCpus())
+	assert.Equal(t, int32(16), server.resources[1].GetMemory())
+	assert.Equal(t, int32(8), server.resources[2].GetCpus())
+	assert.Equal(t, "nvidia-tesla-t4", *server.resources[2].Accelerators[0])
+	assert.Equal(t, int32(1), server.resources[2].GetAcceleratorCount())
+}
+
+func TestGetDurableAndEphemeralComputeConfigurationsShareOffers(t *testing.T) {
+	server := newWispServer(t, 3600)
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+
+	var wg sync.WaitGroup
+	var durableConfigs, ephemeralConfigs *[]ultron.ComputeConfiguration
+	var durableErr, ephemeralErr error
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		durableConfigs, durableErr = client.GetDurableComputeConfigurations(context.Background())
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		ephemeralConfigs, ephemeralErr = client.GetEphemeralComputeConfigurations(context.Background())
+	}()
+
+	wg.Wait()
+
+	assert.NoError(t, durableErr)
+	assert.NoError(t, ephemeralErr)
+	assert.Equal(t, 1, len(*durableConfigs))
+	assert.Equal(t, 1, len(*ephemeralConfigs))
+	assert.Equal(t, 1, len(server.resources), "Expected the offers to be queried once for both compute types")
+}
+
+func TestGetComputeAcceleratorsAndOfferReports(t *testing.T) {
+	server := newWispServer(t, 3600)
+	server.response = `{"choice": [
+		{"cloud": "aws", "instance_type": "p3.2xlarge", "regions": ["us-east-1", "us-west-2"], "cpus": 8, "memory": 61, "price": 3.06, "use_spot": false, "accelerators": {"V100": 1}},
+		{"cloud": "aws", "instance_type": "p3.2xlarge", "regions": ["us-east-1"], "cpus": 8, "memory": 61, "price": 0.92, "use_spot": true, "accelerators": {"V100": 1}},
+		{"cloud": "aws", "instance_type": "m5.large", "regions": ["us-east-1"], "cpus": 2, "memory": 8, "disk_size": 100, "price": 0.096, "use_spot": false},
+		{"cloud": "gcp", "instance_type": "a2-highgpu-1g", "regions": ["us-central1"], "memory": 85, "price": 3.67}
+	]}`
+
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+
+	var reports []wrapper.WispOfferReport
+	client.ReportHandler = func(report wrapper.WispOfferReport) {
+		reports = append(reports, report)
+	}
+
+	configs, err := client.GetAllComputeConfigurations(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(*configs))
+
+	accelerators, err := client.GetComputeAccelerators(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []attendant.ComputeAccelerators{
+		{Provider: "aws", Identifier: "p3.2xlarge", Location: "us-east-1", Accelerators: map[string]int64{"V100": 1}},
+		{Provider: "aws", Identifier: "p3.2xlarge", Location: "us-west-2", Accelerators: map[string]int64{"V100": 1}},
+	}, *accelerators)
+
+	assert.Equal(t, 1, len(server.resources), "Expected the accelerators to come from the fetched offers")
+	assert.Equal(t, 3, len(reports), "Expected each report to be handled once")
+	assert.Equal(t, []string{"missing disk_size"}, reports[0].Warnings)
+	assert.Equal(t, "a2-highgpu-1g", reports[2].InstanceType)
+	assert.Equal(t, []string{"missing cpus"}, reports[2].Errors)
+}
@@ -0,0 +1,199 @@
+package wisp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	wisp "github.com/wispcompute/wisp-go-sdk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ConstrainProjectName = "ultron-attendant"
+
+	LabelGkeAccelerator = "cloud.google.com/gke-accelerator"
+	LabelGpuProduct     = "nvidia.com/gpu.product"
+
+	ResourceNvidiaGpu corev1.ResourceName = "nvidia.com/gpu"
+
+	bytesPerGb = 1 << 30
+)
+
+// WispDemand is a machine shape the cluster runs or needs, in whole vCPUs and GB of memory.
+type WispDemand struct {
+	Cpus             int32
+	MemoryGb         int32
+	Accelerator      string
+	AcceleratorCount int32
+}
+
+// GetDemands collects the shapes of the cluster's nodes and the resource requests of its unscheduled pods,
+// deduplicated and in a stable order.
+func (wc *WispClient) GetDemands(ctx context.Context) ([]WispDemand, error) {
+	if wc.KubernetesService == nil {
+		return nil, fmt.Errorf("kubernetes service is not configured")
+	}
+
+	nodes, err := wc.KubernetesService.GetNodes(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+
+	pods, err := wc.KubernetesService.GetPods(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	var demands []WispDemand
+
+	for _, node := range nodes {
+		demands = append(demands, getNodeDemand(&node))
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+			continue
+		}
+
+		demands = append(demands, getPodDemand(&pod))
+	}
+
+	return dedupeDemands(demands), nil
+}
+
+func (d *WispDemand) ToConstrainRequest() wisp.ConstrainRequest {
+	resources := wisp.Resources{
+		Accelerators: []*string{},
+	}
+
+	if d.Cpus > 0 {
+		resources.SetCpus(d.Cpus)
+	}
+
+	if d.MemoryGb > 0 {
+		resources.SetMemory(d.MemoryGb)
+	}
+
+	if d.Accelerator != "" {
+		accelerator := d.Accelerator
+		resources.Accelerators = []*string{&accelerator}
+	}
+
+	if d.AcceleratorCount > 0 {
+		resources.SetAcceleratorCount(d.AcceleratorCount)
+	}
+
+	return wisp.ConstrainRequest{
+		Project:   wisp.Project{Name: ConstrainProjectName},
+		Resources: resources,
+	}
+}
+
+func getNodeDemand(node *corev1.Node) WispDemand {
+	capacity := node.Status.Capacity
+
+	return WispDemand{
+		Cpus:             toCpus(capacity.Cpu()),
+		MemoryGb:         toMemoryGb(capacity.Memory()),
+		Accelerator:      getAccelerator(node.Labels),
+		AcceleratorCount: toCount(capacity, ResourceNvidiaGpu),
+	}
+}
+
+// getPodDemand follows the scheduler: containers run together, init containers run one at a time before
+// them, so a pod needs the larger of the summed container requests and the largest init container request.
+func getPodDemand(pod *corev1.Pod) WispDemand {
+	requests := corev1.ResourceList{}
+
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity
+			}
+		}
+	}
+
+	for name, quantity := range pod.Spec.Overhead {
+		total := requests[name]
+		total.Add(quantity)
+		requests[name] = total
+	}
+
+	return WispDemand{
+		Cpus:             toCpus(requests.Cpu()),
+		MemoryGb:         toMemoryGb(requests.Memory()),
+		Accelerator:      getAccelerator(pod.Spec.NodeSelector),
+		AcceleratorCount: toCount(requests, ResourceNvidiaGpu),
+	}
+}
+
+func getAccelerator(labels map[string]string) string {
+	for _, label := range []string{LabelGkeAccelerator, LabelGpuProduct} {
+		if accelerator, ok := labels[label]; ok && accelerator != "" {
+			return accelerator
+		}
+	}
+
+	return ""
+}
+
+func toCpus(quantity *resource.Quantity) int32 {
+	return int32((quantity.MilliValue() + 999) / 1000)
+}
+
+func toMemoryGb(quantity *resource.Quantity) int32 {
+	return int32((quantity.Value() + bytesPerGb - 1) / bytesPerGb)
+}
+
+func toCount(resources corev1.ResourceList, name corev1.ResourceName) int32 {
+	quantity, ok := resources[name]
+	if !ok {
+		return 0
+	}
+
+	return int32(quantity.Value())
+}
+
+func dedupeDemands(demands []WispDemand) []WispDemand {
+	seen := make(map[WispDemand]bool)
+
+	var results []WispDemand
+
+	for _, demand := range demands {
+		if (demand.Cpus == 0 && demand.MemoryGb == 0 && demand.AcceleratorCount == 0) || seen[demand] {
+			continue
+		}
+
+		seen[demand] = true
+		results = append(results, demand)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Cpus != results[j].Cpus {
+			return results[i].Cpus < results[j].Cpus
+		}
+
+		if results[i].MemoryGb != results[j].MemoryGb {
+			return results[i].MemoryGb < results[j].MemoryGb
+		}
+
+		if results[i].Accelerator != results[j].Accelerator {
+			return results[i].Accelerator < results[j].Accelerator
+		}
+
+		return results[i].AcceleratorCount < results[j].AcceleratorCount
+	})
+
+	return results
+}
@@ -0,0 +1,262 @@
+package wisp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	wisp "github.com/wispcompute/wisp-go-sdk"
+)
+
+const DefaultCurrency = "USD"
+
+// WispOffer is one region of a cluster offer, with the accelerators that ultron.ComputeConfiguration has no
+// field for.
+type WispOffer struct {
+	Configuration ultron.ComputeConfiguration
+	InstanceType  string
+	Zone          string
+	Accelerators  map[string]int64
+}
+
+// WispOfferReport lists what was wrong with a cluster offer. Errors mean the offer was dropped, warnings
+// mean a field was missing and left unset.
+type WispOfferReport struct {
+	Index        int
+	Cloud        string
+	InstanceType string
+	Errors       []string
+	Warnings     []string
+}
+
+func (r *WispOfferReport) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *WispOfferReport) addError(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *WispOfferReport) addWarning(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// mapOffers emits one offer per region of the cluster offer. An offer without a cloud, region, vCPU count,
+// memory size or price cannot be matched or priced by ultron and is dropped.
+func mapOffers(index int, clusterOffer *wisp.ClusterOffer) ([]WispOffer, WispOfferReport) {
+	report := WispOfferReport{
+		Index:        index,
+		Cloud:        clusterOffer.GetCloud(),
+		InstanceType: clusterOffer.GetInstanceType(),
+	}
+
+	provider, hasProvider := getString(clusterOffer.Cloud.Get())
+	if !hasProvider {
+		report.addError("missing cloud")
+	}
+
+	regions := parseStrings(clusterOffer.Regions)
+	if len(regions) == 0 {
+		if region, ok := getString(clusterOffer.Region.Get()); ok {
+			regions = []string{region}
+		}
+	}
+
+	if len(regions) == 0 {
+		report.addError("missing regions")
+	}
+
+	if clusterOffer.Cpus.Get() == nil {
+		report.addError("missing cpus")
+	}
+
+	if clusterOffer.Memory.Get() == nil {
+		report.addError("missing memory")
+	}
+
+	if clusterOffer.Price.Get() == nil {
+		report.addError("missing price")
+	}
+
+	accelerators, err := parseAccelerators(clusterOffer.Accelerators, clusterOffer.AcceleratorCount)
+	if err != nil {
+		report.addError("invalid accelerators: %v", err)
+	}
+
+	if !report.IsValid() {
+		return nil, report
+	}
+
+	computeType := ultron.ComputeTypeDurable
+	if clusterOffer.UseSpot.Get() == nil {
+		report.addWarning("missing use_spot, assuming on-demand")
+	} else if *clusterOffer.UseSpot.Get() {
+		computeType = ultron.ComputeTypeEphemeral
+	}
+
+	instanceType, hasInstanceType := getString(clusterOffer.InstanceType.Get())
+	if !hasInstanceType {
+		report.addWarning("missing instance_type")
+	}
+
+	zone, _ := getString(clusterOffer.Zone.Get())
+
+	var diskSize *int64
+	if clusterOffer.DiskSize.Get() == nil {
+		report.addWarning("missing disk_size")
+	} else {
+		diskSize = clusterOffer.DiskSize.Get()
+	}
+
+	var offers []WispOffer
+
+	for _, region := range regions {
+		offerProvider := provider
+		location := region
+		cpuCount := *clusterOffer.Cpus.Get()
+		memorySize := *clusterOffer.Memory.Get()
+		price := *clusterOffer.Price.Get()
+		priceUnit := attendant.CostUnitHours
+		priceCurrency := DefaultCurrency
+
+		configuration := ultron.ComputeConfiguration{
+			Provider: &offerProvider,
+			Location: &location,
+			VCpu:     &cpuCount,
+			RamGb:    &memorySize,
+			VolumeGb: diskSize,
+			Cost: &ultron.ComputeCost{
+				Unit:         &priceUnit,
+				Currency:     &priceCurrency,
+				PricePerUnit: &price,
+			},
+			ComputeType: computeType,
+		}
+
+		if hasInstanceType {
+			identifier := instanceType
+			configuration.Identifier = &identifier
+		}
+
+		if zone != "" {
+			dataCenter := zone
+			configuration.DataCenter = &dataCenter
+		}
+
+		if tier, ok := getString(clusterOffer.DiskTier.Get()); ok {
+			configuration.VolumeType = &tier
+		}
+
+		offers = append(offers, WispOffer{
+			Configuration: configuration,
+			InstanceType:  instanceType,
+			Zone:          zone,
+			Accelerators:  accelerators,
+		})
+	}
+
+	return offers, report
+}
+
+func getString(value *string) (string, bool) {
+	if value == nil || *value == "" {
+		return "", false
+	}
+
+	return *value, true
+}
+
+// parseStrings reads a JSON value that may be a single string or a list of strings, since untyped fields of
+// the SDK decode lists as []interface{}.
+func parseStrings(value interface{}) []string {
+	var results []string
+
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			results = append(results, v)
+		}
+	case []string:
+		for _, s := range v {
+			if s != "" {
+				results = append(results, s)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				results = append(results, s)
+			}
+		}
+	}
+
+	return results
+}
+
+// parseAccelerators reads accelerators given as a name to count map ({"A100": 8}), a "name:count" string or a
+// list of names. Counts missing from the accelerators themselves are taken from acceleratorCount.
+func parseAccelerators(value interface{}, acceleratorCount interface{}) (map[string]int64, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	defaultCount, err := parseCount(acceleratorCount, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	accelerators := make(map[string]int64)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, count := range v {
+			parsed, err := parseCount(count, defaultCount)
+			if err != nil {
+				return nil, err
+			}
+
+			accelerators[name] = parsed
+		}
+	default:
+		for _, item := range parseStrings(value) {
+			name, count, found := strings.Cut(item, ":")
+			if !found {
+				accelerators[name] = defaultCount
+
+				continue
+			}
+
+			parsed, err := strconv.ParseInt(strings.TrimSpace(count), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid count for %s: %v", name, err)
+			}
+
+			accelerators[name] = parsed
+		}
+	}
+
+	if len(accelerators) == 0 {
+		return nil, nil
+	}
+
+	return accelerators, nil
+}
+
+func parseCount(value interface{}, defaultCount int64) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return defaultCount, nil
+	case float64:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	}
+
+	return 0, fmt.Errorf("unexpected count: %v", value)
+}
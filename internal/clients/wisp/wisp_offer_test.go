@@ -0,0 +1,66 @@
+package wisp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	wrapper "github.com/be-heroes/ultron-attendant/internal/clients/wisp"
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetOffers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == wrapper.TokenPath {
+			fmt.Fprint(w, `{"access_token": "token", "expires_in": 3600}`)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"choice": [
+			{"cloud": "aws", "instance_type": "g5.xlarge", "regions": ["us-east-1", "us-west-2"], "zone": "us-east-1a", "cpus": 4, "memory": 16, "disk_size": 256, "disk_tier": "medium", "price": 1.006, "use_spot": true, "accelerators": {"A10G": 1}},
+			{"cloud": "gcp", "instance_type": "a2-highgpu-2g", "region": "us-central1", "cpus": 24, "memory": 170, "price": 7.348, "accelerators": "A100:2"},
+			{"cloud": "azure", "instance_type": "Standard_D2s_v5", "cpus": 2, "memory": 8, "price": 0.096},
+			{"cloud": "lambda", "regions": ["us-east-1"], "memory": 8}
+		]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := wrapper.NewWispClient(&attendant.Config{WispApiUrl: server.URL, WispClientId: "client-id", WispClientSecret: "client-secret"}, newKubernetesService(t, []corev1.Node{newNode("4", "16Gi", nil)}, nil))
+
+	offers, reports, err := client.GetOffers(context.Background())
+	assert.NoError(t, err, "Expected invalid offers not to fail the request")
+	assert.Equal(t, 3, len(*offers), "Expected one offer per region of every valid cluster offer")
+
+	first := (*offers)[0]
+	assert.Equal(t, "aws", *first.Configuration.Provider)
+	assert.Equal(t, "us-east-1", *first.Configuration.Location)
+	assert.Equal(t, "us-east-1a", *first.Configuration.DataCenter)
+	assert.Equal(t, "g5.xlarge", *first.Configuration.Identifier)
+	assert.Equal(t, int64(256), *first.Configuration.VolumeGb)
+	assert.Equal(t, "medium", *first.Configuration.VolumeType)
+	assert.Equal(t, ultron.ComputeTypeEphemeral, first.Configuration.ComputeType)
+	assert.Equal(t, map[string]int64{"A10G": 1}, first.Accelerators)
+	assert.Equal(t, "us-west-2", *(*offers)[1].Configuration.Location)
+
+	second := (*offers)[2]
+	assert.Equal(t, "us-central1", *second.Configuration.Location)
+	assert.Nil(t, second.Configuration.VolumeGb, "Expected a missing disk size to be left unset")
+	assert.Equal(t, ultron.ComputeTypeDurable, second.Configuration.ComputeType)
+	assert.Equal(t, map[string]int64{"A100": 2}, second.Accelerators)
+
+	assert.Equal(t, 3, len(*reports))
+	assert.True(t, (*reports)[0].IsValid())
+	assert.Equal(t, []string{"missing use_spot, assuming on-demand", "missing disk_size"}, (*reports)[0].Warnings)
+	assert.Equal(t, "Standard_D2s_v5", (*reports)[1].InstanceType)
+	assert.Equal(t, []string{"missing regions"}, (*reports)[1].Errors)
+	assert.Equal(t, 3, (*reports)[2].Index)
+	assert.Equal(t, []string{"missing cpus", "missing price"}, (*reports)[2].Errors)
+}
@@ -0,0 +1,163 @@
+package wisp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TokenPath            = "/o/token/"
+	TokenExpirySkew      = 30 * time.Second
+	DefaultTokenLifetime = 5 * time.Minute
+)
+
+// WispAuthError is returned when the API or the token endpoint rejects the credentials, as opposed to a
+// transport or server failure.
+type WispAuthError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *WispAuthError) Error() string {
+	return fmt.Sprintf("wisp authentication failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+type IWispTokenSource interface {
+	GetToken(ctx context.Context) (string, error)
+	Invalidate()
+}
+
+type WispTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// WispCredentialsTokenSource obtains bearer tokens with the OAuth2 client credentials grant and caches them
+// until shortly before they expire.
+type WispCredentialsTokenSource struct {
+	httpClient   *http.Client
+	tokenUrl     string
+	clientId     string
+	clientSecret string
+	mutex        sync.Mutex
+	token        string
+	expiresAt    time.Time
+	now          func() time.Time
+}
+
+func NewWispCredentialsTokenSource(httpClient *http.Client, apiUrl string, clientId string, clientSecret string) *WispCredentialsTokenSource {
+	return &WispCredentialsTokenSource{
+		httpClient:   httpClient,
+		tokenUrl:     strings.TrimSuffix(apiUrl, "/") + TokenPath,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		now:          time.Now,
+	}
+}
+
+func (s *WispCredentialsTokenSource) GetToken(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	if s.clientId == "" || s.clientSecret == "" {
+		return "", &WispAuthError{StatusCode: http.StatusUnauthorized, Message: "missing client credentials"}
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", s.clientId)
+	form.Set("client_secret", s.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		body, _ := io.ReadAll(resp.Body)
+
+		return "", &WispAuthError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		return "", fmt.Errorf("failed to request token: %v", string(body))
+	}
+
+	var tokenResponse WispTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %v", err)
+	}
+
+	if tokenResponse.AccessToken == "" {
+		return "", &WispAuthError{StatusCode: resp.StatusCode, Message: "token response has no access token"}
+	}
+
+	lifetime := DefaultTokenLifetime
+	if tokenResponse.ExpiresIn > 0 {
+		lifetime = time.Duration(tokenResponse.ExpiresIn) * time.Second
+	}
+
+	s.token = tokenResponse.AccessToken
+	s.expiresAt = s.now().Add(lifetime - TokenExpirySkew)
+
+	return s.token, nil
+}
+
+func (s *WispCredentialsTokenSource) Invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.token = ""
+}
+
+// WispFileTokenSource reads a token from a mounted file on every call, so that a token rotated by an
+// external process is picked up without restarting.
+type WispFileTokenSource struct {
+	path string
+}
+
+func NewWispFileTokenSource(path string) *WispFileTokenSource {
+	return &WispFileTokenSource{
+		path: path,
+	}
+}
+
+func (s *WispFileTokenSource) GetToken(ctx context.Context) (string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %v", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", &WispAuthError{StatusCode: http.StatusUnauthorized, Message: "token file is empty"}
+	}
+
+	return token, nil
+}
+
+func (s *WispFileTokenSource) Invalidate() {}
@@ -4,12 +4,21 @@ import (
 	"context"
 	"fmt"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
+	autoscaler "github.com/be-heroes/ultron-attendant/internal/clients/autoscaler"
+	aws "github.com/be-heroes/ultron-attendant/internal/clients/aws"
+	azure "github.com/be-heroes/ultron-attendant/internal/clients/azure"
 	emma "github.com/be-heroes/ultron-attendant/internal/clients/emma"
+	gcp "github.com/be-heroes/ultron-attendant/internal/clients/gcp"
+	jarvis "github.com/be-heroes/ultron-attendant/internal/clients/jarvis"
+	onprem "github.com/be-heroes/ultron-attendant/internal/clients/onprem"
+	static "github.com/be-heroes/ultron-attendant/internal/clients/static"
+	wisp "github.com/be-heroes/ultron-attendant/internal/clients/wisp"
 	attendant "github.com/be-heroes/ultron-attendant/pkg"
 	ultron "github.com/be-heroes/ultron/pkg"
 	algorithm "github.com/be-heroes/ultron/pkg/algorithm"
@@ -43,15 +52,122 @@ func main() {
 	cacheService := services.NewCacheService(nil, redisClient)
 	computeService := services.NewComputeService(algorithmInstance, cacheService, mapperInstance)
 	kubernetesClient, err := attendant.InitializeKubernetesServiceFromConfig(config)
-	emmaClient := emma.NewEmmaClient(config.EmmaClientId, config.EmmaClientSecret)
 	if err != nil {
 		sugar.Fatalw("Failed to initialize Kubernetes client", "error", err)
 	}
 
+	emmaClient := emma.NewEmmaClient(config.EmmaClientId, config.EmmaClientSecret)
+	emmaClient.Filter = config.EmmaFilter
+
+	providerRegistry := attendant.NewProviderRegistry(config.EnabledProviders)
+
+	for _, name := range providerRegistry.GetUnknownProviders() {
+		sugar.Warnw("Ignoring unknown provider", "provider", name, "variable", attendant.EnvEnabledProviders)
+	}
+
+	if err := providerRegistry.Register(emmaClient); err != nil {
+		sugar.Fatalw("Failed to register provider", "error", err)
+	}
+
+	wispClient := wisp.NewWispClient(config, kubernetesClient)
+	wispClient.ReportHandler = func(report wisp.WispOfferReport) {
+		if report.IsValid() {
+			sugar.Infow("Wisp offer is missing optional fields", "index", report.Index, "cloud", report.Cloud, "instanceType", report.InstanceType, "warnings", report.Warnings)
+		} else {
+			sugar.Warnw("Dropped invalid Wisp offer", "index", report.Index, "cloud", report.Cloud, "instanceType", report.InstanceType, "errors", report.Errors, "warnings", report.Warnings)
+		}
+	}
+
+	if err := providerRegistry.Register(wispClient); err != nil {
+		sugar.Fatalw("Failed to register provider", "error", err)
+	}
+
+	if err := providerRegistry.Register(onprem.NewOnPremClient(config, kubernetesClient)); err != nil {
+		sugar.Fatalw("Failed to register provider", "error", err)
+	}
+
+	staticClient := static.NewStaticClient(config)
+	if err := providerRegistry.Register(staticClient); err != nil {
+		sugar.Fatalw("Failed to register provider", "error", err)
+	}
+
+	azureClient := azure.NewAzureClient(nil, azure.DefaultBaseUrl)
+	azureClient.Regions = config.AzureRegions
+	azureClient.CurrencyCode = config.AzureCurrencyCode
+	azureClient.ApiVersion = config.AzureApiVersion
+
+	if err := providerRegistry.Register(azureClient); err != nil {
+		sugar.Fatalw("Failed to register provider", "error", err)
+	}
+
+	// The AWS and GCP clients load credentials when they are created, so they are only created when enabled.
+	if providerRegistry.IsEnabled(attendant.ProviderNameAws) {
+		if len(config.AwsRegions) == 0 {
+			sugar.Fatalw("Failed to initialize AWS client", "error", "no AWS regions configured")
+		}
+
+		awsClient, err := aws.NewAwsClient(config.AwsRegions[0])
+		if err != nil {
+			sugar.Fatalw("Failed to initialize AWS client", "error", err)
+		}
+
+		awsClient.Regions = config.AwsRegions
+
+		if err := providerRegistry.Register(awsClient); err != nil {
+			sugar.Fatalw("Failed to register provider", "error", err)
+		}
+	}
+
+	if providerRegistry.IsEnabled(attendant.ProviderNameGcp) {
+		gcpClient, err := gcp.NewGcpClient(config)
+		if err != nil {
+			sugar.Fatalw("Failed to initialize GCP client", "error", err)
+		}
+
+		gcpClient.Regions = config.GcpRegions
+
+		if err := providerRegistry.Register(gcpClient); err != nil {
+			sugar.Fatalw("Failed to register provider", "error", err)
+		}
+	}
+
+	var jarvisClient jarvis.IJarvisClient
+
+	if config.JarvisApiUrl != "" {
+		client, err := jarvis.NewJarvisClient(config)
+		if err != nil {
+			sugar.Fatalw("Failed to initialize Jarvis client", "error", err)
+		}
+
+		jarvisClient = client
+	}
+
+	var autoscalerClient autoscaler.IAutoscalerClient
+
+	if client, err := autoscaler.NewAutoscalerClient(config); err != nil {
+		sugar.Warnw("Failed to initialize autoscaler discovery, launchable configurations are not cached", "error", err)
+	} else {
+		autoscalerClient = client
+	}
+
+	for _, provider := range providerRegistry.GetEnabledProviders() {
+		sugar.Infow("Provider enabled", "provider", provider.GetName(), "capabilities", provider.GetCapabilities())
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	go startCacheRefreshLoop(ctx, sugar, emmaClient, config, cacheService, kubernetesClient, computeService, mapperInstance)
+	if providerRegistry.IsEnabled(attendant.ProviderNameStatic) {
+		if err := staticClient.Load(); err != nil {
+			sugar.Fatalw("Failed to load static catalog", "error", err)
+		}
+
+		go staticClient.Watch(ctx, func(err error) {
+			sugar.Warnw("Failed to reload static catalog, keeping the last valid catalog", "error", err)
+		})
+	}
+
+	go startCacheRefreshLoop(ctx, sugar, providerRegistry, attendant.NewProviderResults(), jarvisClient, autoscalerClient, config, cacheService, kubernetesClient, computeService, mapperInstance)
 
 	<-ctx.Done()
 
@@ -62,7 +178,7 @@ func main() {
 	sugar.Info("Ultron-attendant shut down gracefully")
 }
 
-func startCacheRefreshLoop(ctx context.Context, logger *zap.SugaredLogger, emmaClient *emma.EmmaClient, config *attendant.Config, cacheService services.ICacheService, kubernetesService services.IKubernetesService, computeService services.IComputeService, mapper mapper.IMapper) {
+func startCacheRefreshLoop(ctx context.Context, logger *zap.SugaredLogger, providerRegistry attendant.IProviderRegistry, providerResults attendant.IProviderResults, jarvisClient jarvis.IJarvisClient, autoscalerClient autoscaler.IAutoscalerClient, config *attendant.Config, cacheService services.ICacheService, kubernetesService services.IKubernetesService, computeService services.IComputeService, mapper mapper.IMapper) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -72,86 +188,104 @@ func startCacheRefreshLoop(ctx context.Context, logger *zap.SugaredLogger, emmaC
 		default:
 			logger.Info("Refreshing cache")
 
-			refreshCache(ctx, logger, emmaClient, cacheService, kubernetesService, computeService, mapper)
+			refreshCache(ctx, logger, providerRegistry, providerResults, jarvisClient, autoscalerClient, cacheService, kubernetesService, computeService, mapper)
 
 			time.Sleep(time.Duration(config.CacheRefreshInterval) * time.Minute)
 		}
 	}
 }
 
-func refreshCache(ctx context.Context, logger *zap.SugaredLogger, emmaClient *emma.EmmaClient, cacheService services.ICacheService, kubernetesService services.IKubernetesService, computeService services.IComputeService, mapper mapper.IMapper) {
+func refreshCache(ctx context.Context, logger *zap.SugaredLogger, providerRegistry attendant.IProviderRegistry, providerResults attendant.IProviderResults, jarvisClient jarvis.IJarvisClient, autoscalerClient autoscaler.IAutoscalerClient, cacheService services.ICacheService, kubernetesService services.IKubernetesService, computeService services.IComputeService, mapper mapper.IMapper) {
 	results := make(chan error, 3)
 
 	go func() {
-		durableConfigs, err := emmaClient.GetDurableComputeConfigurations(ctx)
-		if err != nil {
-			results <- fmt.Errorf("failed to fetch durable configs: %v", err)
-		} else {
-			cacheService.AddCacheItem(ultron.CacheKeyDurableComputeConfigurations, durableConfigs, 0)
-			results <- nil
+		if err := providerResults.Refresh(ctx, providerRegistry); err != nil {
+			logger.Warnw("Failed to fetch configs, keeping the last fetched configs of the failing providers", "error", err)
 		}
-	}()
 
-	go func() {
-		ephemeralConfigs, err := emmaClient.GetEphemeralComputeConfigurations(ctx)
-		if err != nil {
-			results <- fmt.Errorf("failed to fetch ephemeral configs: %v", err)
-		} else {
-			cacheService.AddCacheItem(ultron.CacheKeyEphemeralComputeConfigurations, ephemeralConfigs, 0)
-			results <- nil
+		durableConfigs := providerResults.GetComputeConfigurations(ultron.ComputeTypeDurable)
+		ephemeralConfigs := providerResults.GetComputeConfigurations(ultron.ComputeTypeEphemeral)
+		durableConfigsByProvider := providerResults.GetComputeConfigurationsByProvider(ultron.ComputeTypeDurable)
+		ephemeralConfigsByProvider := providerResults.GetComputeConfigurationsByProvider(ultron.ComputeTypeEphemeral)
+		providerConfigs := providerResults.GetResults()
+
+		cacheService.AddCacheItem(ultron.CacheKeyDurableComputeConfigurations, &durableConfigs, 0)
+		cacheService.AddCacheItem(ultron.CacheKeyEphemeralComputeConfigurations, &ephemeralConfigs, 0)
+		cacheService.AddCacheItem(attendant.CacheKeyDurableComputeConfigurationsByProvider, &durableConfigsByProvider, 0)
+		cacheService.AddCacheItem(attendant.CacheKeyEphemeralComputeConfigurationsByProvider, &ephemeralConfigsByProvider, 0)
+		cacheService.AddCacheItem(attendant.CacheKeyProviderComputeConfigurations, &providerConfigs, 0)
+
+		var accelerators []attendant.ComputeAccelerators
+
+		for _, provider := range providerRegistry.GetEnabledProviders() {
+			acceleratorProvider, ok := provider.(attendant.IAcceleratorProvider)
+			if !ok {
+				continue
+			}
+
+			providerAccelerators, err := acceleratorProvider.GetComputeAccelerators(ctx)
+			if err != nil {
+				logger.Warnw("Failed to fetch accelerators", "provider", provider.GetName(), "error", err)
+
+				continue
+			}
+
+			accelerators = append(accelerators, *providerAccelerators...)
 		}
-	}()
 
-	go func() {
-		nodes, err := kubernetesService.GetNodes(context.Background(), metav1.ListOptions{})
-		if err != nil {
-			results <- err
-		} else {
-			var wNodes []ultron.WeightedNode
+		cacheService.AddCacheItem(attendant.CacheKeyComputeAccelerators, &accelerators, 0)
 
-			for _, node := range nodes {
-				wNode, err := mapper.MapNodeToWeightedNode(&node)
-				if err != nil {
-					results <- fmt.Errorf("failed to map to weighted nodes: %v", err)
-				}
+		configs := append(append([]ultron.ComputeConfiguration{}, durableConfigs...), ephemeralConfigs...)
 
-				computeConfiguration, err := computeService.MatchWeightedNodeToComputeConfiguration(&wNode)
-				if err != nil {
-					results <- fmt.Errorf("failed to match compute configuration: %v", err)
-				}
+		results <- refreshLaunchableConfigurations(ctx, autoscalerClient, cacheService, kubernetesService, configs)
+	}()
 
-				if computeConfiguration != nil && computeConfiguration.Cost != nil && computeConfiguration.Cost.PricePerUnit != nil {
-					wNode.Weights[ultron.WeightKeyPrice] = float64(*computeConfiguration.Cost.PricePerUnit)
-				}
+	go func() {
+		var effectiveCosts []attendant.EffectiveComputeCost
 
-				medianPrice, err := computeService.CalculateWeightedNodeMedianPrice(&wNode)
-				if err != nil {
-					results <- fmt.Errorf("failed to calculate median price: %v", err)
-				}
+		for _, provider := range providerRegistry.GetEnabledProviders() {
+			costProvider, ok := provider.(attendant.IEffectiveCostProvider)
+			if !ok {
+				continue
+			}
 
-				wNode.Weights[ultron.WeightKeyPriceMedian] = medianPrice
+			costs, err := costProvider.GetEffectiveComputeCosts(ctx)
+			if err != nil {
+				logger.Warnw("Failed to fetch effective costs", "provider", provider.GetName(), "error", err)
 
-				interuptionRate, err := computeService.GetInteruptionRateForWeightedNode(&wNode)
-				if err != nil {
-					results <- fmt.Errorf("failed to get interuption rate for weighted node: %v", err)
-				}
+				continue
+			}
 
-				wNode.InterruptionRate = *interuptionRate
+			effectiveCosts = append(effectiveCosts, *costs...)
+		}
 
-				latencyRate, err := computeService.GetLatencyRateForWeightedNode(&wNode)
-				if err != nil {
-					results <- fmt.Errorf("failed to get latency rate for weighted node: %v", err)
-				}
+		cacheService.AddCacheItem(attendant.CacheKeyEffectiveComputeCosts, &effectiveCosts, 0)
 
-				wNode.LatencyRate = *latencyRate
+		var reservedCosts []attendant.ReservedComputeCost
 
-				wNodes = append(wNodes, wNode)
+		for _, provider := range providerRegistry.GetEnabledProviders() {
+			costProvider, ok := provider.(attendant.IReservedCostProvider)
+			if !ok {
+				continue
 			}
 
-			cacheService.AddCacheItem(ultron.CacheKeyWeightedNodes, wNodes, 0)
+			costs, err := costProvider.GetReservedComputeCosts(ctx)
+			if err != nil {
+				logger.Warnw("Failed to fetch reserved costs", "provider", provider.GetName(), "error", err)
 
-			results <- nil
+				continue
+			}
+
+			reservedCosts = append(reservedCosts, *costs...)
 		}
+
+		cacheService.AddCacheItem(attendant.CacheKeyReservedComputeCosts, &reservedCosts, 0)
+
+		results <- nil
+	}()
+
+	go func() {
+		results <- refreshWeightedNodes(ctx, logger, jarvisClient, cacheService, kubernetesService, computeService, mapper)
 	}()
 
 	for i := 0; i < 3; i++ {
@@ -162,3 +296,110 @@ func refreshCache(ctx context.Context, logger *zap.SugaredLogger, emmaClient *em
 
 	logger.Info("Cache refresh complete")
 }
+
+// refreshLaunchableConfigurations caches the configurations the cluster's autoscalers may launch, out of the
+// configurations fetched from the providers, limited to the regions the cluster runs in.
+func refreshLaunchableConfigurations(ctx context.Context, autoscalerClient autoscaler.IAutoscalerClient, cacheService services.ICacheService, kubernetesService services.IKubernetesService, configs []ultron.ComputeConfiguration) error {
+	if autoscalerClient == nil {
+		return nil
+	}
+
+	nodes, err := kubernetesService.GetNodes(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var regions []string
+
+	for _, node := range nodes {
+		if region := node.Labels[autoscaler.LabelTopologyRegion]; region != "" && !slices.Contains(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+
+	launchableConfigs, err := autoscalerClient.GetLaunchableComputeConfigurations(ctx, configs, regions)
+	if err != nil {
+		return fmt.Errorf("failed to discover launchable configurations: %v", err)
+	}
+
+	cacheService.AddCacheItem(attendant.CacheKeyLaunchableComputeConfigurations, launchableConfigs, 0)
+
+	return nil
+}
+
+// refreshWeightedNodes weighs the cluster's nodes. Their interruption and latency rates are predicted by Jarvis
+// when it is configured, and looked up through the compute service for nodes Jarvis has no prediction for.
+func refreshWeightedNodes(ctx context.Context, logger *zap.SugaredLogger, jarvisClient jarvis.IJarvisClient, cacheService services.ICacheService, kubernetesService services.IKubernetesService, computeService services.IComputeService, mapper mapper.IMapper) error {
+	nodes, err := kubernetesService.GetNodes(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var wNodes []ultron.WeightedNode
+	var jarvisConfigurations []jarvis.JarvisConfiguration
+
+	for _, node := range nodes {
+		wNode, err := mapper.MapNodeToWeightedNode(&node)
+		if err != nil {
+			logger.Warnw("Failed to map to weighted node", "node", node.Name, "error", err)
+
+			continue
+		}
+
+		computeConfiguration, err := computeService.MatchWeightedNodeToComputeConfiguration(&wNode)
+		if err != nil {
+			logger.Warnw("Failed to match compute configuration", "node", node.Name, "error", err)
+		}
+
+		if computeConfiguration != nil && computeConfiguration.Cost != nil && computeConfiguration.Cost.PricePerUnit != nil {
+			wNode.Weights[ultron.WeightKeyPrice] = float64(*computeConfiguration.Cost.PricePerUnit)
+		}
+
+		medianPrice, err := computeService.CalculateWeightedNodeMedianPrice(&wNode)
+		if err != nil {
+			logger.Warnw("Failed to calculate median price", "node", node.Name, "error", err)
+		}
+
+		wNode.Weights[ultron.WeightKeyPriceMedian] = medianPrice
+
+		wNodes = append(wNodes, wNode)
+		jarvisConfigurations = append(jarvisConfigurations, jarvis.NewJarvisConfiguration(wNode.Annotations[ultron.AnnotationInstanceType], computeConfiguration))
+	}
+
+	var predictions map[string]jarvis.JarvisPrediction
+
+	if jarvisClient != nil {
+		predictions, err = jarvisClient.GetPredictions(ctx, jarvisConfigurations)
+		if err != nil {
+			logger.Warnw("Failed to fetch Jarvis predictions", "error", err)
+		}
+	}
+
+	for i := range wNodes {
+		wNode := &wNodes[i]
+
+		// Rates Jarvis has no prediction for, or that it is not available for at all, are computed locally.
+		prediction := predictions[jarvisConfigurations[i].Key()]
+		selector := map[string]string{ultron.LabelInstanceType: wNode.Annotations[ultron.AnnotationInstanceType]}
+
+		if prediction.InterruptionRate != nil {
+			wNode.InterruptionRate = ultron.WeightedInteruptionRate{Selector: selector, Weight: *prediction.InterruptionRate}
+		} else if interuptionRate, err := computeService.GetInteruptionRateForWeightedNode(wNode); err != nil {
+			logger.Warnw("Failed to get interuption rate for weighted node", "error", err)
+		} else if interuptionRate != nil {
+			wNode.InterruptionRate = *interuptionRate
+		}
+
+		if prediction.LatencyRate != nil {
+			wNode.LatencyRate = ultron.WeightedLatencyRate{Selector: selector, Weight: *prediction.LatencyRate}
+		} else if latencyRate, err := computeService.GetLatencyRateForWeightedNode(wNode); err != nil {
+			logger.Warnw("Failed to get latency rate for weighted node", "error", err)
+		} else if latencyRate != nil {
+			wNode.LatencyRate = *latencyRate
+		}
+	}
+
+	cacheService.AddCacheItem(ultron.CacheKeyWeightedNodes, wNodes, 0)
+
+	return nil
+}
@@ -1,8 +1,68 @@
 package pkg
 
 const (
+	CacheKeyComputeAccelerators                      = "ULTRON_ATTENDANT_COMPUTE_ACCELERATORS"
+	CacheKeyDurableComputeConfigurationsByProvider   = "ULTRON_ATTENDANT_DURABLE_COMPUTE_CONFIGURATIONS_BY_PROVIDER"
+	CacheKeyEffectiveComputeCosts                    = "ULTRON_ATTENDANT_EFFECTIVE_COMPUTE_COSTS"
+	CacheKeyEphemeralComputeConfigurationsByProvider = "ULTRON_ATTENDANT_EPHEMERAL_COMPUTE_CONFIGURATIONS_BY_PROVIDER"
+	CacheKeyLaunchableComputeConfigurations          = "ULTRON_ATTENDANT_LAUNCHABLE_COMPUTE_CONFIGURATIONS"
+	CacheKeyProviderComputeConfigurations            = "ULTRON_ATTENDANT_PROVIDER_COMPUTE_CONFIGURATIONS"
+	CacheKeyReservedComputeCosts                     = "ULTRON_ATTENDANT_RESERVED_COMPUTE_COSTS"
+
+	CostUnitHours    = "HOURS"
+	CostUnitMonths   = "MONTHS"
+	CostUnitYears    = "YEARS"
+	CostUnitQuantity = "QUANTITY"
+
+	DefaultAwsRegions       = "us-east-1"
+	DefaultAzureRegions     = "eastus"
+	DefaultEnabledProviders = ProviderNameEmma
+
+	EnvAwsRegions           = "ULTRON_ATTENDANT_AWS_REGIONS"
+	EnvAzureRegions         = "ULTRON_ATTENDANT_AZURE_REGIONS"
+	EnvAzureCurrencyCode    = "ULTRON_ATTENDANT_AZURE_CURRENCY_CODE"
+	EnvAzureApiVersion      = "ULTRON_ATTENDANT_AZURE_API_VERSION"
 	EnvCacheRefreshInterval = "ULTRON_ATTENDANT_CACHE_REFRESH_INTERVAL"
+	EnvEnabledProviders     = "ULTRON_ATTENDANT_ENABLED_PROVIDERS"
+	EnvGcpBillingProjectId  = "ULTRON_ATTENDANT_GCP_BILLING_PROJECT_ID"
+	EnvGcpBillingDatasetId  = "ULTRON_ATTENDANT_GCP_BILLING_DATASET_ID"
+	EnvGcpBillingTableId    = "ULTRON_ATTENDANT_GCP_BILLING_TABLE_ID"
+	EnvGcpProjectId         = "ULTRON_ATTENDANT_GCP_PROJECT_ID"
+	EnvGcpRegions           = "ULTRON_ATTENDANT_GCP_REGIONS"
 	EnvGoogleCredentials    = "GOOGLE_APPLICATION_CREDENTIALS"
 	EnvEmmaClientId         = "EMMA_CLIENT_ID"
 	EnvEmmaClientSecret     = "EMMA_CLIENT_SECRET"
+	EnvEmmaProviderId       = "ULTRON_ATTENDANT_EMMA_PROVIDER_ID"
+	EnvEmmaLocationId       = "ULTRON_ATTENDANT_EMMA_LOCATION_ID"
+	EnvEmmaVCpuMin          = "ULTRON_ATTENDANT_EMMA_VCPU_MIN"
+	EnvEmmaVCpuMax          = "ULTRON_ATTENDANT_EMMA_VCPU_MAX"
+	EnvEmmaRamGbMin         = "ULTRON_ATTENDANT_EMMA_RAM_GB_MIN"
+	EnvEmmaRamGbMax         = "ULTRON_ATTENDANT_EMMA_RAM_GB_MAX"
+	EnvEmmaVolumeGbMin      = "ULTRON_ATTENDANT_EMMA_VOLUME_GB_MIN"
+	EnvEmmaVolumeGbMax      = "ULTRON_ATTENDANT_EMMA_VOLUME_GB_MAX"
+	EnvEmmaPriceMin         = "ULTRON_ATTENDANT_EMMA_PRICE_MIN"
+	EnvEmmaPriceMax         = "ULTRON_ATTENDANT_EMMA_PRICE_MAX"
+	EnvEmmaPageSize         = "ULTRON_ATTENDANT_EMMA_PAGE_SIZE"
+	EnvJarvisApiUrl         = "ULTRON_ATTENDANT_JARVIS_API_URL"
+	EnvJarvisApiToken       = "JARVIS_API_TOKEN"
+	EnvOnPremCostModelFile  = "ULTRON_ATTENDANT_ONPREM_COST_MODEL_FILE"
+	EnvStaticCatalogPaths   = "ULTRON_ATTENDANT_STATIC_CATALOG_PATHS"
+	EnvWispApiUrl           = "ULTRON_ATTENDANT_WISP_API_URL"
+	EnvWispTokenFile        = "ULTRON_ATTENDANT_WISP_TOKEN_FILE"
+	EnvWispClientId         = "WISP_CLIENT_ID"
+	EnvWispClientSecret     = "WISP_CLIENT_SECRET"
+
+	HoursPerYear = 8760
+
+	ProviderCapabilityDurable   ProviderCapability = "durable"
+	ProviderCapabilityEphemeral ProviderCapability = "ephemeral"
+	ProviderCapabilityCostOnly  ProviderCapability = "cost-only"
+
+	ProviderNameAws    = "aws"
+	ProviderNameAzure  = "azure"
+	ProviderNameEmma   = "emma"
+	ProviderNameGcp    = "gcp"
+	ProviderNameOnPrem = "onprem"
+	ProviderNameStatic = "static"
+	ProviderNameWisp   = "wisp"
 )
@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultFetchCacheTtl = time.Minute
+
+type fetchCacheCall[T any] struct {
+	done      chan struct{}
+	value     T
+	err       error
+	expiresAt time.Time
+}
+
+// FetchCache keeps the result of a fetch per key for a short time and lets concurrent callers share a fetch
+// that is in flight, so that the durable and ephemeral fetches of one refresh cycle download a catalog once.
+// Failed fetches are not kept. The zero value is ready to use.
+type FetchCache[T any] struct {
+	mutex sync.Mutex
+	calls map[string]*fetchCacheCall[T]
+}
+
+func (c *FetchCache[T]) Get(ctx context.Context, key string, ttl time.Duration, fetch func(ctx context.Context) (T, error)) (T, error) {
+	c.mutex.Lock()
+
+	if c.calls == nil {
+		c.calls = make(map[string]*fetchCacheCall[T])
+	}
+
+	if call, ok := c.calls[key]; ok {
+		select {
+		case <-call.done:
+			if call.err == nil && time.Now().Before(call.expiresAt) {
+				c.mutex.Unlock()
+
+				return call.value, nil
+			}
+		default:
+			c.mutex.Unlock()
+
+			return c.wait(ctx, call)
+		}
+	}
+
+	call := &fetchCacheCall[T]{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mutex.Unlock()
+
+	call.value, call.err = fetch(ctx)
+	call.expiresAt = time.Now().Add(ttl)
+	close(call.done)
+
+	return call.value, call.err
+}
+
+func (c *FetchCache[T]) wait(ctx context.Context, call *fetchCacheCall[T]) (T, error) {
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero T
+
+		return zero, ctx.Err()
+	}
+}
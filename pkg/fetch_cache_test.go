@@ -0,0 +1,80 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchCacheSharesFetchesPerKey(t *testing.T) {
+	var cache attendant.FetchCache[string]
+	var fetches atomic.Int32
+
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		fetches.Add(1)
+		<-release
+
+		return "catalog", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+
+	for i := range results {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i], _ = cache.Get(context.Background(), "us-east-1", time.Hour, fetch)
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), fetches.Load())
+	assert.Equal(t, []string{"catalog", "catalog", "catalog", "catalog"}, results)
+
+	_, err := cache.Get(context.Background(), "us-east-1", time.Hour, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	_, err = cache.Get(context.Background(), "eu-west-1", time.Hour, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestFetchCacheRefetchesExpiredAndFailedFetches(t *testing.T) {
+	var cache attendant.FetchCache[int]
+	var fetches atomic.Int32
+
+	failing := func(ctx context.Context) (int, error) {
+		fetches.Add(1)
+
+		return 0, errors.New("unavailable")
+	}
+
+	succeeding := func(ctx context.Context) (int, error) {
+		return int(fetches.Add(1)), nil
+	}
+
+	_, err := cache.Get(context.Background(), "key", time.Hour, failing)
+	assert.EqualError(t, err, "unavailable")
+
+	value, err := cache.Get(context.Background(), "key", 0, succeeding)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	value, err = cache.Get(context.Background(), "key", time.Hour, succeeding)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	ultron "github.com/be-heroes/ultron/pkg"
 	services "github.com/be-heroes/ultron/pkg/services"
@@ -20,18 +21,100 @@ func LoadConfig() (*Config, error) {
 		refreshInterval = 15
 	}
 
+	emmaFilter, err := loadEmmaFilter()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		RedisServerAddress:   os.Getenv(ultron.EnvRedisServerAddress),
 		RedisServerPassword:  os.Getenv(ultron.EnvRedisServerPassword),
 		RedisServerDatabase:  redisDatabase,
 		EmmaClientId:         os.Getenv(EnvEmmaClientId),
 		EmmaClientSecret:     os.Getenv(EnvEmmaClientSecret),
+		EmmaFilter:           *emmaFilter,
 		KubernetesConfigPath: os.Getenv(ultron.EnvKubernetesConfig),
 		KubernetesMasterUrl:  fmt.Sprintf("https://%s:%s", os.Getenv(ultron.EnvKubernetesServiceHost), os.Getenv(ultron.EnvKubernetesServicePort)),
 		CacheRefreshInterval: refreshInterval,
+		EnabledProviders:     parseCSV(getEnvWithDefault(EnvEnabledProviders, DefaultEnabledProviders)),
+		GcpBillingProjectId:  os.Getenv(EnvGcpBillingProjectId),
+		GcpBillingDatasetId:  os.Getenv(EnvGcpBillingDatasetId),
+		GcpBillingTableId:    os.Getenv(EnvGcpBillingTableId),
+		GcpProjectId:         os.Getenv(EnvGcpProjectId),
+		WispApiUrl:           os.Getenv(EnvWispApiUrl),
+		WispClientId:         os.Getenv(EnvWispClientId),
+		WispClientSecret:     os.Getenv(EnvWispClientSecret),
+		WispTokenFile:        os.Getenv(EnvWispTokenFile),
+		GcpCredentialsFile:   os.Getenv(EnvGoogleCredentials),
+		JarvisApiUrl:         os.Getenv(EnvJarvisApiUrl),
+		JarvisApiToken:       os.Getenv(EnvJarvisApiToken),
+		StaticCatalogPaths:   parseCSV(os.Getenv(EnvStaticCatalogPaths)),
+		OnPremCostModelFile:  os.Getenv(EnvOnPremCostModelFile),
+		AwsRegions:           parseCSV(getEnvWithDefault(EnvAwsRegions, DefaultAwsRegions)),
+		AzureRegions:         parseCSV(getEnvWithDefault(EnvAzureRegions, DefaultAzureRegions)),
+		AzureCurrencyCode:    os.Getenv(EnvAzureCurrencyCode),
+		AzureApiVersion:      os.Getenv(EnvAzureApiVersion),
+		GcpRegions:           parseCSV(os.Getenv(EnvGcpRegions)),
 	}, nil
 }
 
+// loadEmmaFilter reads the emma filter section. Unlike the other settings a malformed value is an error, since
+// ignoring it would silently widen or narrow the cached catalog.
+func loadEmmaFilter() (*EmmaFilter, error) {
+	var filter EmmaFilter
+
+	int32Values := map[string]*int32{
+		EnvEmmaProviderId:  &filter.ProviderId,
+		EnvEmmaLocationId:  &filter.LocationId,
+		EnvEmmaVCpuMin:     &filter.VCpuMin,
+		EnvEmmaVCpuMax:     &filter.VCpuMax,
+		EnvEmmaRamGbMin:    &filter.RamGbMin,
+		EnvEmmaRamGbMax:    &filter.RamGbMax,
+		EnvEmmaVolumeGbMin: &filter.VolumeGbMin,
+		EnvEmmaVolumeGbMax: &filter.VolumeGbMax,
+		EnvEmmaPageSize:    &filter.PageSize,
+	}
+
+	for envVar, target := range int32Values {
+		value := os.Getenv(envVar)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid value for %s: %s", envVar, value)
+		}
+
+		*target = int32(parsed)
+	}
+
+	float32Values := map[string]*float32{
+		EnvEmmaPriceMin: &filter.PriceMin,
+		EnvEmmaPriceMax: &filter.PriceMax,
+	}
+
+	for envVar, target := range float32Values {
+		value := os.Getenv(envVar)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 32)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid value for %s: %s", envVar, value)
+		}
+
+		*target = float32(parsed)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
 func InitializeKubernetesServiceFromConfig(config *Config) (kubernetesService services.IKubernetesService, err error) {
 	kubernetesService, err = services.NewKubernetesService(config.KubernetesMasterUrl, config.KubernetesConfigPath, false)
 	if err != nil {
@@ -40,3 +123,47 @@ func InitializeKubernetesServiceFromConfig(config *Config) (kubernetesService se
 
 	return kubernetesService, nil
 }
+
+// NormalizeCostUnit maps the unit spellings used by the provider price lists ("Hrs", "1 Hour", "Month", ...)
+// onto the unit names used by emma ("HOURS", "MONTHS"), so costs from different providers can be compared.
+func NormalizeCostUnit(unit string) string {
+	normalized := strings.ToLower(strings.TrimSpace(unit))
+	normalized = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(normalized, "1/"), "1 "))
+
+	switch normalized {
+	case "hr", "hrs", "hour", "hours", "hourly":
+		return CostUnitHours
+	case "mo", "month", "months", "monthly":
+		return CostUnitMonths
+	case "yr", "yrs", "year", "years", "yearly":
+		return CostUnitYears
+	case "quantity":
+		return CostUnitQuantity
+	}
+
+	return strings.ToUpper(normalized)
+}
+
+func getEnvWithDefault(envVar, defaultValue string) string {
+	value := os.Getenv(envVar)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+func parseCSV(value string) []string {
+	var result []string
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
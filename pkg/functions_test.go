@@ -0,0 +1,68 @@
+package pkg_test
+
+import (
+	"testing"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCostUnit(t *testing.T) {
+	cases := map[string]string{
+		"Hrs":      attendant.CostUnitHours,
+		"1 Hour":   attendant.CostUnitHours,
+		"1/Hour":   attendant.CostUnitHours,
+		"HOURS":    attendant.CostUnitHours,
+		"1/Month":  attendant.CostUnitMonths,
+		"MONTHS":   attendant.CostUnitMonths,
+		"1 Year":   attendant.CostUnitYears,
+		"Quantity": attendant.CostUnitQuantity,
+		"GB-Mo":    "GB-MO",
+		"":         "",
+	}
+
+	for unit, expected := range cases {
+		assert.Equal(t, expected, attendant.NormalizeCostUnit(unit), "Unexpected normalized unit for %q", unit)
+	}
+}
+
+func TestLoadConfigEmmaFilter(t *testing.T) {
+	t.Setenv(attendant.EnvEmmaProviderId, "3")
+	t.Setenv(attendant.EnvEmmaVCpuMin, "2")
+	t.Setenv(attendant.EnvEmmaVCpuMax, "16")
+	t.Setenv(attendant.EnvEmmaPriceMax, "0.5")
+
+	config, err := attendant.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, attendant.EmmaFilter{ProviderId: 3, VCpuMin: 2, VCpuMax: 16, PriceMax: 0.5}, config.EmmaFilter)
+
+	t.Setenv(attendant.EnvEmmaVCpuMin, "32")
+
+	_, err = attendant.LoadConfig()
+	assert.EqualError(t, err, "invalid emma vCPU filter: minimum 32 is above maximum 16")
+
+	t.Setenv(attendant.EnvEmmaRamGbMin, "lots")
+
+	_, err = attendant.LoadConfig()
+	assert.EqualError(t, err, "invalid value for ULTRON_ATTENDANT_EMMA_RAM_GB_MIN: lots")
+}
+
+func TestLoadConfigProviderRegions(t *testing.T) {
+	config, err := attendant.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{attendant.DefaultAwsRegions}, config.AwsRegions)
+	assert.Equal(t, []string{attendant.DefaultAzureRegions}, config.AzureRegions)
+	assert.Empty(t, config.GcpRegions, "Expected every GCP region to be fetched by default")
+
+	t.Setenv(attendant.EnvAwsRegions, "eu-north-1, eu-west-1")
+	t.Setenv(attendant.EnvGcpRegions, "europe-north1")
+	t.Setenv(attendant.EnvAzureCurrencyCode, "EUR")
+	t.Setenv(attendant.EnvAzureApiVersion, "2023-01-01-preview")
+
+	config, err = attendant.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eu-north-1", "eu-west-1"}, config.AwsRegions)
+	assert.Equal(t, []string{"europe-north1"}, config.GcpRegions)
+	assert.Equal(t, "EUR", config.AzureCurrencyCode)
+	assert.Equal(t, "2023-01-01-preview", config.AzureApiVersion)
+}
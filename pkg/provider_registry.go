@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	ultron "github.com/be-heroes/ultron/pkg"
+)
+
+type IProvider interface {
+	GetName() string
+	GetCapabilities() []ProviderCapability
+	GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+	GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error)
+}
+
+// IEffectiveCostProvider is implemented by providers that can report actual spend from billing data.
+type IEffectiveCostProvider interface {
+	GetEffectiveComputeCosts(ctx context.Context) (*[]EffectiveComputeCost, error)
+}
+
+// IReservedCostProvider is implemented by providers that price reserved or committed capacity.
+type IReservedCostProvider interface {
+	GetReservedComputeCosts(ctx context.Context) (*[]ReservedComputeCost, error)
+}
+
+// IAcceleratorProvider is implemented by providers whose configurations can carry accelerators.
+type IAcceleratorProvider interface {
+	GetComputeAccelerators(ctx context.Context) (*[]ComputeAccelerators, error)
+}
+
+type IProviderRegistry interface {
+	Register(provider IProvider) error
+	GetProvider(name string) (IProvider, error)
+	GetProviders() []IProvider
+	GetEnabledProviders() []IProvider
+	GetEnabledProvidersWithCapability(capability ProviderCapability) []IProvider
+	IsEnabled(name string) bool
+	GetUnknownProviders() []string
+}
+
+var providerNames = []string{ProviderNameAws, ProviderNameAzure, ProviderNameEmma, ProviderNameGcp, ProviderNameOnPrem, ProviderNameStatic, ProviderNameWisp}
+
+type ProviderRegistry struct {
+	mutex            sync.RWMutex
+	providers        []IProvider
+	enabledProviders map[string]bool
+	unknownProviders []string
+}
+
+// NewProviderRegistry enables the named providers. Names that match no provider, such as typos, are kept
+// aside so that they can be reported instead of silently leaving a provider disabled.
+func NewProviderRegistry(enabledProviders []string) *ProviderRegistry {
+	enabled := make(map[string]bool)
+
+	var unknown []string
+
+	for _, name := range enabledProviders {
+		if !slices.Contains(providerNames, strings.ToLower(name)) {
+			unknown = append(unknown, name)
+
+			continue
+		}
+
+		enabled[strings.ToLower(name)] = true
+	}
+
+	return &ProviderRegistry{
+		enabledProviders: enabled,
+		unknownProviders: unknown,
+	}
+}
+
+func (r *ProviderRegistry) Register(provider IProvider) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, registered := range r.providers {
+		if strings.EqualFold(registered.GetName(), provider.GetName()) {
+			return fmt.Errorf("provider already registered: %s", provider.GetName())
+		}
+	}
+
+	r.providers = append(r.providers, provider)
+
+	return nil
+}
+
+func (r *ProviderRegistry) GetProvider(name string) (IProvider, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, provider := range r.providers {
+		if strings.EqualFold(provider.GetName(), name) {
+			return provider, nil
+		}
+	}
+
+	return nil, fmt.Errorf("provider not registered: %s", name)
+}
+
+func (r *ProviderRegistry) GetProviders() []IProvider {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return slices.Clone(r.providers)
+}
+
+func (r *ProviderRegistry) GetEnabledProviders() []IProvider {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var result []IProvider
+
+	for _, provider := range r.providers {
+		if r.enabledProviders[strings.ToLower(provider.GetName())] {
+			result = append(result, provider)
+		}
+	}
+
+	return result
+}
+
+func (r *ProviderRegistry) GetEnabledProvidersWithCapability(capability ProviderCapability) []IProvider {
+	var result []IProvider
+
+	for _, provider := range r.GetEnabledProviders() {
+		if slices.Contains(provider.GetCapabilities(), capability) {
+			result = append(result, provider)
+		}
+	}
+
+	return result
+}
+
+func (r *ProviderRegistry) IsEnabled(name string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.enabledProviders[strings.ToLower(name)]
+}
+
+// GetUnknownProviders returns the enabled provider names that match no provider.
+func (r *ProviderRegistry) GetUnknownProviders() []string {
+	return slices.Clone(r.unknownProviders)
+}
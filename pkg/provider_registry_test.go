@@ -0,0 +1,81 @@
+package pkg_test
+
+import (
+	"context"
+	"testing"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	name         string
+	capabilities []attendant.ProviderCapability
+}
+
+func (p *fakeProvider) GetName() string {
+	return p.name
+}
+
+func (p *fakeProvider) GetCapabilities() []attendant.ProviderCapability {
+	return p.capabilities
+}
+
+func (p *fakeProvider) GetAllComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return &[]ultron.ComputeConfiguration{}, nil
+}
+
+func (p *fakeProvider) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return &[]ultron.ComputeConfiguration{}, nil
+}
+
+func (p *fakeProvider) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return &[]ultron.ComputeConfiguration{}, nil
+}
+
+func TestProviderRegistryRegister(t *testing.T) {
+	registry := attendant.NewProviderRegistry([]string{attendant.ProviderNameEmma})
+
+	err := registry.Register(&fakeProvider{name: attendant.ProviderNameEmma})
+	assert.NoError(t, err, "Expected no error when registering a provider")
+
+	err = registry.Register(&fakeProvider{name: "EMMA"})
+	assert.Error(t, err, "Expected an error when registering a duplicate provider")
+
+	provider, err := registry.GetProvider(attendant.ProviderNameEmma)
+	assert.NoError(t, err, "Expected no error from GetProvider")
+	assert.Equal(t, attendant.ProviderNameEmma, provider.GetName(), "Provider name does not match")
+
+	_, err = registry.GetProvider(attendant.ProviderNameWisp)
+	assert.Error(t, err, "Expected an error for an unregistered provider")
+}
+
+func TestProviderRegistryEnabledProviders(t *testing.T) {
+	registry := attendant.NewProviderRegistry([]string{"Emma", attendant.ProviderNameAws})
+
+	registry.Register(&fakeProvider{name: attendant.ProviderNameEmma, capabilities: []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}})
+	registry.Register(&fakeProvider{name: attendant.ProviderNameWisp, capabilities: []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}})
+	registry.Register(&fakeProvider{name: attendant.ProviderNameAws, capabilities: []attendant.ProviderCapability{attendant.ProviderCapabilityCostOnly}})
+
+	assert.Equal(t, 3, len(registry.GetProviders()), "Expected number of providers does not match")
+	assert.Equal(t, 2, len(registry.GetEnabledProviders()), "Expected number of enabled providers does not match")
+	assert.True(t, registry.IsEnabled(attendant.ProviderNameEmma), "Expected emma to be enabled")
+	assert.False(t, registry.IsEnabled(attendant.ProviderNameWisp), "Expected wisp to be disabled")
+
+	durableProviders := registry.GetEnabledProvidersWithCapability(attendant.ProviderCapabilityDurable)
+	assert.Equal(t, 1, len(durableProviders), "Expected number of durable providers does not match")
+	assert.Equal(t, attendant.ProviderNameEmma, durableProviders[0].GetName(), "Provider name does not match")
+
+	costOnlyProviders := registry.GetEnabledProvidersWithCapability(attendant.ProviderCapabilityCostOnly)
+	assert.Equal(t, 1, len(costOnlyProviders), "Expected number of cost-only providers does not match")
+	assert.Equal(t, attendant.ProviderNameAws, costOnlyProviders[0].GetName(), "Provider name does not match")
+}
+
+func TestProviderRegistryUnknownProviders(t *testing.T) {
+	registry := attendant.NewProviderRegistry([]string{attendant.ProviderNameEmma, "wsip", "AWS"})
+
+	assert.Equal(t, []string{"wsip"}, registry.GetUnknownProviders(), "Expected the misspelled provider to be reported")
+	assert.True(t, registry.IsEnabled(attendant.ProviderNameAws), "Expected provider names to be case insensitive")
+	assert.False(t, registry.IsEnabled("wsip"), "Expected an unknown provider not to be enabled")
+}
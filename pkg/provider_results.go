@@ -0,0 +1,195 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	ultron "github.com/be-heroes/ultron/pkg"
+)
+
+// ProviderResult is the last set of configurations of one compute type a provider returned. Error holds the
+// failure of the latest fetch, in which case the configurations are the ones of the fetch before it.
+type ProviderResult struct {
+	Provider       string
+	ComputeType    ultron.ComputeType
+	Configurations []ultron.ComputeConfiguration
+	RefreshedAt    time.Time
+	Error          string
+}
+
+type IProviderResults interface {
+	Refresh(ctx context.Context, registry IProviderRegistry) error
+	GetComputeConfigurations(computeType ultron.ComputeType) []ultron.ComputeConfiguration
+	GetComputeConfigurationsByProvider(computeType ultron.ComputeType) map[string][]ultron.ComputeConfiguration
+	GetResults() []ProviderResult
+}
+
+// ProviderResults keeps the configurations each provider returned last, so that a provider failing a refresh
+// keeps serving its previous configurations instead of dropping out of the cache.
+type ProviderResults struct {
+	mutex   sync.RWMutex
+	results map[string]*ProviderResult
+	now     func() time.Time
+}
+
+func NewProviderResults() *ProviderResults {
+	return &ProviderResults{
+		results: make(map[string]*ProviderResult),
+		now:     time.Now,
+	}
+}
+
+// Refresh fetches the durable and ephemeral configurations of every enabled provider concurrently. The
+// failures of all providers are returned together once every fetch has finished.
+func (r *ProviderResults) Refresh(ctx context.Context, registry IProviderRegistry) error {
+	var wg sync.WaitGroup
+	var errs []error
+	var errsMutex sync.Mutex
+
+	for _, provider := range registry.GetEnabledProviders() {
+		for _, computeType := range getComputeTypes(provider) {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				var configs *[]ultron.ComputeConfiguration
+				var err error
+
+				if computeType == ultron.ComputeTypeEphemeral {
+					configs, err = provider.GetEphemeralComputeConfigurations(ctx)
+				} else {
+					configs, err = provider.GetDurableComputeConfigurations(ctx)
+				}
+
+				if err != nil {
+					err = fmt.Errorf("failed to fetch %s configurations from %s: %v", computeType, provider.GetName(), err)
+
+					errsMutex.Lock()
+					errs = append(errs, err)
+					errsMutex.Unlock()
+
+					r.update(provider.GetName(), computeType, nil, err)
+
+					return
+				}
+
+				if configs == nil {
+					configs = &[]ultron.ComputeConfiguration{}
+				}
+
+				r.update(provider.GetName(), computeType, *configs, nil)
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return errors.Join(errs...)
+}
+
+// GetComputeConfigurations merges the configurations of a compute type of all providers, ordered by provider.
+func (r *ProviderResults) GetComputeConfigurations(computeType ultron.ComputeType) []ultron.ComputeConfiguration {
+	result := []ultron.ComputeConfiguration{}
+
+	for _, providerResult := range r.GetResults() {
+		if providerResult.ComputeType == computeType {
+			result = append(result, providerResult.Configurations...)
+		}
+	}
+
+	return result
+}
+
+// GetComputeConfigurationsByProvider returns the configurations of a compute type keyed by the provider they
+// were fetched from. Brokers name the cloud a configuration runs on, so the merged configurations cannot tell
+// them apart from the configurations of that cloud's own provider.
+func (r *ProviderResults) GetComputeConfigurationsByProvider(computeType ultron.ComputeType) map[string][]ultron.ComputeConfiguration {
+	result := make(map[string][]ultron.ComputeConfiguration)
+
+	for _, providerResult := range r.GetResults() {
+		if providerResult.ComputeType == computeType {
+			result[providerResult.Provider] = providerResult.Configurations
+		}
+	}
+
+	return result
+}
+
+// GetResults returns the results of every provider and compute type, ordered by provider and compute type.
+func (r *ProviderResults) GetResults() []ProviderResult {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]string, 0, len(r.results))
+	for key := range r.results {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make([]ProviderResult, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *r.results[key])
+	}
+
+	return result
+}
+
+// update replaces the configurations of a provider when the fetch succeeded, and only records the error when
+// it failed. Configurations that do not name a provider are tagged with the one they came from; the others
+// keep theirs, since brokers such as emma and Wisp name the cloud the configuration runs on.
+func (r *ProviderResults) update(provider string, computeType ultron.ComputeType, configs []ultron.ComputeConfiguration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := provider + "/" + string(computeType)
+
+	result, ok := r.results[key]
+	if !ok {
+		result = &ProviderResult{Provider: provider, ComputeType: computeType, Configurations: []ultron.ComputeConfiguration{}}
+		r.results[key] = result
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+
+		return
+	}
+
+	tagged := make([]ultron.ComputeConfiguration, len(configs))
+
+	for i, config := range configs {
+		if config.Provider == nil || *config.Provider == "" {
+			source := provider
+			config.Provider = &source
+		}
+
+		tagged[i] = config
+	}
+
+	result.Configurations = tagged
+	result.RefreshedAt = r.now()
+	result.Error = ""
+}
+
+func getComputeTypes(provider IProvider) []ultron.ComputeType {
+	var computeTypes []ultron.ComputeType
+
+	for _, capability := range provider.GetCapabilities() {
+		switch capability {
+		case ProviderCapabilityDurable:
+			computeTypes = append(computeTypes, ultron.ComputeTypeDurable)
+		case ProviderCapabilityEphemeral:
+			computeTypes = append(computeTypes, ultron.ComputeTypeEphemeral)
+		}
+	}
+
+	return computeTypes
+}
@@ -0,0 +1,173 @@
+package pkg_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	attendant "github.com/be-heroes/ultron-attendant/pkg"
+	ultron "github.com/be-heroes/ultron/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+type fetchingProvider struct {
+	fakeProvider
+	durable   []ultron.ComputeConfiguration
+	ephemeral []ultron.ComputeConfiguration
+	err       error
+	delay     time.Duration
+	inFlight  *atomic.Int32
+	maxFlight *atomic.Int32
+}
+
+func (p *fetchingProvider) fetch(configs []ultron.ComputeConfiguration) (*[]ultron.ComputeConfiguration, error) {
+	if p.inFlight != nil {
+		current := p.inFlight.Add(1)
+		defer p.inFlight.Add(-1)
+
+		for {
+			peak := p.maxFlight.Load()
+			if current <= peak || p.maxFlight.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+	}
+
+	time.Sleep(p.delay)
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return &configs, nil
+}
+
+func (p *fetchingProvider) GetDurableComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return p.fetch(p.durable)
+}
+
+func (p *fetchingProvider) GetEphemeralComputeConfigurations(ctx context.Context) (*[]ultron.ComputeConfiguration, error) {
+	return p.fetch(p.ephemeral)
+}
+
+func newComputeConfiguration(identifier string, provider string, computeType ultron.ComputeType) ultron.ComputeConfiguration {
+	configuration := ultron.ComputeConfiguration{Identifier: &identifier, ComputeType: computeType}
+
+	if provider != "" {
+		configuration.Provider = &provider
+	}
+
+	return configuration
+}
+
+func getIdentifiers(configs []ultron.ComputeConfiguration) []string {
+	result := []string{}
+
+	for _, config := range configs {
+		result = append(result, *config.Provider+":"+*config.Identifier)
+	}
+
+	return result
+}
+
+func TestProviderResultsRefresh(t *testing.T) {
+	capabilities := []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+
+	emmaProvider := &fetchingProvider{
+		fakeProvider: fakeProvider{name: attendant.ProviderNameEmma, capabilities: capabilities},
+		durable:      []ultron.ComputeConfiguration{newComputeConfiguration("101", "AWS", ultron.ComputeTypeDurable)},
+		ephemeral:    []ultron.ComputeConfiguration{newComputeConfiguration("102", "AWS", ultron.ComputeTypeEphemeral)},
+	}
+	staticProvider := &fetchingProvider{
+		fakeProvider: fakeProvider{name: attendant.ProviderNameStatic, capabilities: []attendant.ProviderCapability{attendant.ProviderCapabilityDurable}},
+		durable:      []ultron.ComputeConfiguration{newComputeConfiguration("bm-64c", "", ultron.ComputeTypeDurable)},
+	}
+	wispProvider := &fetchingProvider{
+		fakeProvider: fakeProvider{name: attendant.ProviderNameWisp, capabilities: capabilities},
+		durable:      []ultron.ComputeConfiguration{newComputeConfiguration("wisp-1", "", ultron.ComputeTypeDurable)},
+	}
+
+	registry := attendant.NewProviderRegistry([]string{attendant.ProviderNameEmma, attendant.ProviderNameStatic, attendant.ProviderNameWisp})
+	registry.Register(wispProvider)
+	registry.Register(emmaProvider)
+	registry.Register(staticProvider)
+	registry.Register(&fetchingProvider{fakeProvider: fakeProvider{name: attendant.ProviderNameAws, capabilities: capabilities}, err: errors.New("not enabled")})
+
+	results := attendant.NewProviderResults()
+
+	err := results.Refresh(context.Background(), registry)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"AWS:101", "static:bm-64c", "wisp:wisp-1"}, getIdentifiers(results.GetComputeConfigurations(ultron.ComputeTypeDurable)), "Expected the durable configurations of the enabled providers tagged with their source")
+	assert.Equal(t, []string{"AWS:102"}, getIdentifiers(results.GetComputeConfigurations(ultron.ComputeTypeEphemeral)))
+	assert.Nil(t, staticProvider.durable[0].Provider, "Expected the configurations of the provider to be left untouched")
+
+	wispProvider.err = errors.New("service unavailable")
+	emmaProvider.durable = append(emmaProvider.durable, newComputeConfiguration("103", "GCP", ultron.ComputeTypeDurable))
+
+	err = results.Refresh(context.Background(), registry)
+	assert.EqualError(t, err, "failed to fetch durable configurations from wisp: service unavailable\nfailed to fetch ephemeral configurations from wisp: service unavailable")
+	assert.Equal(t, []string{"AWS:101", "GCP:103", "static:bm-64c", "wisp:wisp-1"}, getIdentifiers(results.GetComputeConfigurations(ultron.ComputeTypeDurable)), "Expected the failing provider to keep its last configurations")
+
+	providerResults := results.GetResults()
+	assert.Equal(t, 5, len(providerResults))
+	assert.Equal(t, attendant.ProviderNameWisp, providerResults[3].Provider)
+	assert.Equal(t, ultron.ComputeTypeDurable, providerResults[3].ComputeType)
+	assert.Equal(t, "failed to fetch durable configurations from wisp: service unavailable", providerResults[3].Error)
+
+	wispProvider.err = nil
+
+	err = results.Refresh(context.Background(), registry)
+	assert.NoError(t, err)
+	assert.Empty(t, results.GetResults()[3].Error, "Expected the error to be cleared once the provider recovers")
+}
+
+func TestProviderResultsByProvider(t *testing.T) {
+	capabilities := []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}
+
+	registry := attendant.NewProviderRegistry([]string{attendant.ProviderNameAws, attendant.ProviderNameEmma})
+	registry.Register(&fetchingProvider{
+		fakeProvider: fakeProvider{name: attendant.ProviderNameAws, capabilities: capabilities},
+		durable:      []ultron.ComputeConfiguration{newComputeConfiguration("m5.large", attendant.ProviderNameAws, ultron.ComputeTypeDurable)},
+	})
+	registry.Register(&fetchingProvider{
+		fakeProvider: fakeProvider{name: attendant.ProviderNameEmma, capabilities: capabilities},
+		durable:      []ultron.ComputeConfiguration{newComputeConfiguration("m5.large", attendant.ProviderNameAws, ultron.ComputeTypeDurable)},
+		ephemeral:    []ultron.ComputeConfiguration{newComputeConfiguration("m5.xlarge", attendant.ProviderNameAws, ultron.ComputeTypeEphemeral)},
+	})
+
+	results := attendant.NewProviderResults()
+
+	err := results.Refresh(context.Background(), registry)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aws:m5.large", "aws:m5.large"}, getIdentifiers(results.GetComputeConfigurations(ultron.ComputeTypeDurable)))
+
+	durable := results.GetComputeConfigurationsByProvider(ultron.ComputeTypeDurable)
+	assert.Equal(t, 2, len(durable), "Expected the configurations of both providers to be kept apart")
+	assert.Equal(t, []string{"aws:m5.large"}, getIdentifiers(durable[attendant.ProviderNameAws]))
+	assert.Equal(t, []string{"aws:m5.large"}, getIdentifiers(durable[attendant.ProviderNameEmma]))
+
+	ephemeral := results.GetComputeConfigurationsByProvider(ultron.ComputeTypeEphemeral)
+	assert.Empty(t, ephemeral[attendant.ProviderNameAws])
+	assert.Equal(t, []string{"aws:m5.xlarge"}, getIdentifiers(ephemeral[attendant.ProviderNameEmma]))
+}
+
+func TestProviderResultsRefreshFetchesConcurrently(t *testing.T) {
+	var inFlight, maxFlight atomic.Int32
+
+	registry := attendant.NewProviderRegistry([]string{attendant.ProviderNameEmma, attendant.ProviderNameWisp})
+
+	for _, name := range []string{attendant.ProviderNameEmma, attendant.ProviderNameWisp} {
+		registry.Register(&fetchingProvider{
+			fakeProvider: fakeProvider{name: name, capabilities: []attendant.ProviderCapability{attendant.ProviderCapabilityDurable, attendant.ProviderCapabilityEphemeral}},
+			delay:        50 * time.Millisecond,
+			inFlight:     &inFlight,
+			maxFlight:    &maxFlight,
+		})
+	}
+
+	err := attendant.NewProviderResults().Refresh(context.Background(), registry)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), maxFlight.Load(), "Expected every provider and compute type to be fetched at the same time")
+}
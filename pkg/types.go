@@ -1,12 +1,114 @@
 package pkg
 
+import (
+	"fmt"
+	"time"
+)
+
+type ProviderCapability string
+
+// EffectiveComputeCost is what a machine type actually cost in a location on one day, after discounts and
+// credits, as opposed to its list price.
+type EffectiveComputeCost struct {
+	Provider            string
+	Identifier          string
+	Location            string
+	Date                time.Time
+	Currency            string
+	Cost                float64
+	Credits             float64
+	UsageHours          float64
+	EffectiveHourlyRate float64
+}
+
+// ReservedComputeCost is the price of reserving a machine type in a location for a term, with the upfront fee
+// amortized over every hour of the term so that EffectiveHourlyRate can be compared with on-demand and spot
+// hourly rates.
+type ReservedComputeCost struct {
+	Provider            string
+	Identifier          string
+	Location            string
+	TermYears           int
+	OfferingClass       string
+	PurchaseOption      string
+	Currency            string
+	UpfrontFee          float64
+	HourlyRate          float64
+	EffectiveHourlyRate float64
+}
+
+// ComputeAccelerators are the GPUs or other accelerators, by name and count, of the configurations with this
+// identifier in a location, which ultron.ComputeConfiguration has no field for.
+type ComputeAccelerators struct {
+	Provider     string
+	Identifier   string
+	Location     string
+	Accelerators map[string]int64
+}
+
+// EmmaFilter narrows the emma configuration queries to the part of the catalog the clusters can use. Zero
+// values leave the corresponding filter unset.
+type EmmaFilter struct {
+	ProviderId  int32
+	LocationId  int32
+	VCpuMin     int32
+	VCpuMax     int32
+	RamGbMin    int32
+	RamGbMax    int32
+	VolumeGbMin int32
+	VolumeGbMax int32
+	PriceMin    float32
+	PriceMax    float32
+	PageSize    int32
+}
+
+func (f *EmmaFilter) Validate() error {
+	ranges := []struct {
+		name     string
+		min, max float64
+	}{
+		{"vCPU", float64(f.VCpuMin), float64(f.VCpuMax)},
+		{"RAM", float64(f.RamGbMin), float64(f.RamGbMax)},
+		{"volume", float64(f.VolumeGbMin), float64(f.VolumeGbMax)},
+		{"price", float64(f.PriceMin), float64(f.PriceMax)},
+	}
+
+	for _, r := range ranges {
+		if r.max > 0 && r.min > r.max {
+			return fmt.Errorf("invalid emma %s filter: minimum %v is above maximum %v", r.name, r.min, r.max)
+		}
+	}
+
+	return nil
+}
+
 type Config struct {
 	RedisServerAddress   string
 	RedisServerPassword  string
 	RedisServerDatabase  int
 	EmmaClientId         string
 	EmmaClientSecret     string
+	EmmaFilter           EmmaFilter
 	KubernetesConfigPath string
 	KubernetesMasterUrl  string
 	CacheRefreshInterval int
+	EnabledProviders     []string
+	GcpBillingProjectId  string
+	GcpBillingDatasetId  string
+	GcpBillingTableId    string
+	GcpProjectId         string
+	WispApiUrl           string
+	WispClientId         string
+	WispClientSecret     string
+	WispTokenFile        string
+	GcpCredentialsFile   string
+	JarvisApiUrl         string
+	JarvisApiToken       string
+	StaticCatalogPaths   []string
+	OnPremCostModelFile  string
+	AwsRegions           []string
+	AzureRegions         []string
+	AzureCurrencyCode    string
+	AzureApiVersion      string
+	GcpRegions           []string
 }